@@ -0,0 +1,58 @@
+//go:build !headless
+// +build !headless
+
+package audio
+
+import (
+	"time"
+
+	"github.com/faiface/beep/speaker"
+)
+
+// setAmbience fades the given ambience bed to its volume, starting or stopping it as needed.
+func (instance *Instance) setAmbience(c CommandSetAmbience) {
+	if instance.null {
+		return
+	}
+	bed, ok := instance.ambience[c.Name]
+	if ok && bed.filepath != c.Filepath {
+		instance.stopAmbience(c.Name, c.Fade)
+		ok = false
+	}
+	if !ok {
+		if c.Volume <= 0 {
+			return
+		}
+		bed = &ambienceBed{filepath: c.Filepath}
+		instance.ambience[c.Name] = bed
+		snd := newSoundFromCommand(CommandNewSound{Filepath: c.Filepath})
+		si, err := snd.playAsMusic(1, -1, c.Fade, instance.mixer.bus(BusAmbience), func(si *SoundInstance) {})
+		if err != nil {
+			instance.log.Errorf("[Audio] could not play ambience %s: %s", c.Name, err)
+			return
+		}
+		bed.si = si
+	} else if bed.si == nil {
+		return
+	} else if c.Volume <= 0 {
+		instance.stopAmbience(c.Name, c.Fade)
+		return
+	}
+	speaker.Lock()
+	bed.si.fader.fadeTo(c.Volume, c.Fade)
+	speaker.Unlock()
+}
+
+// stopAmbience fades out and stops the given ambience bed.
+func (instance *Instance) stopAmbience(name string, fadeOut time.Duration) {
+	bed, ok := instance.ambience[name]
+	if !ok {
+		return
+	}
+	if bed.si != nil {
+		speaker.Lock()
+		bed.si.fader.fadeOut(fadeOut)
+		speaker.Unlock()
+	}
+	delete(instance.ambience, name)
+}
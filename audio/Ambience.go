@@ -0,0 +1,7 @@
+package audio
+
+// ambienceBed is a looping ambience bed. Its SoundInstance is nil if the bed could not be played, so that it is not retried until its file changes.
+type ambienceBed struct {
+	filepath string
+	si       *SoundInstance
+}
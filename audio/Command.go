@@ -1,5 +1,7 @@
 package audio
 
+import "time"
+
 // CommandI is the interface for our audio Command messages.
 type CommandI interface {
 }
@@ -11,11 +13,32 @@ type CommandNewSound struct {
 	Filepath string
 }
 
+// CommandRemoveSound forgets the sound matching the given ID, such as once its cached file has been evicted. Voices that are still playing it finish normally.
+type CommandRemoveSound struct {
+	ID uint32
+}
+
 // CommandPlaySound starts playing sounds matching the given ID.
 type CommandPlaySound struct {
 	ID             uint32
 	Volume         float32    // 0-1
 	ChannelVolumes [8]float64 // I have no idea about this.
+	Bus            Bus        // Bus to play through, BusEffects by default.
+	VoiceID        uint32     // Identifies a positional sound for CommandOccludeSound.
+	Positional     bool       // Pans and attenuates the sound relative to the listener.
+	Y, X, Z        float64    // Position of a positional sound.
+	Occlusion      int        // Number of opaque tiles between a positional sound and the listener.
+}
+
+// CommandMoveListener moves the position positional sounds are heard from.
+type CommandMoveListener struct {
+	Y, X, Z float64
+}
+
+// CommandOccludeSound updates the number of opaque tiles between a playing positional sound and the listener.
+type CommandOccludeSound struct {
+	VoiceID   uint32
+	Occlusion int
 }
 
 // CommandStopSound stops playing all sounds matching ID.
@@ -27,15 +50,55 @@ type CommandStopSound struct {
 type CommandPlayMusic struct {
 	ID             uint32
 	PlaybackID     uint32
-	Volume         float32    // 0-1
-	ChannelVolumes [8]float64 // I have no idea about this.
+	Volume         float32       // 0-1
+	ChannelVolumes [8]float64    // I have no idea about this.
+	Loop           int           // Number of times to play the music, or -1 to play it forever. Zero plays it once.
+	FadeIn         time.Duration // Time the music takes to fade in.
+	Crossfade      bool          // Fades out all other music over FadeIn.
 }
 
 // CommandStopMusic stops playing music matching the given ID.
 type CommandStopMusic struct {
 	PlaybackID uint32
+	FadeOut    time.Duration // Time the music takes to fade out before it stops.
 }
 
-// CommandStopAllMusic stops all playing music.
+// CommandStopAllMusic stops all playing music and clears the music queue.
 type CommandStopAllMusic struct {
+	FadeOut time.Duration // Time the music takes to fade out before it stops.
+}
+
+// CommandQueueMusic queues music to play once the current music ends. If no music is playing or all of it plays forever, the queued music starts right away and crossfades with the current music.
+type CommandQueueMusic struct {
+	CommandPlayMusic
+}
+
+// CommandClearMusicQueue removes all queued music.
+type CommandClearMusicQueue struct {
+}
+
+// CommandSetVolume sets the volume of a mixer bus.
+type CommandSetVolume struct {
+	Bus    Bus
+	Volume float64 // 0-1
+}
+
+// CommandSetMute mutes or unmutes a mixer bus.
+type CommandSetMute struct {
+	Bus   Bus
+	Muted bool
+}
+
+// CommandSetVoiceLimits sets how many sounds may play at once, in total and per sound. Once a limit is reached, the quietest or else the oldest sound is stopped to make room. Limits that are not positive use their defaults.
+type CommandSetVoiceLimits struct {
+	Total    int
+	PerSound int
+}
+
+// CommandSetAmbience fades a looping ambience bed on BusAmbience to the given volume. The bed starts playing from Filepath if it is not playing yet and stops once faded to zero.
+type CommandSetAmbience struct {
+	Name     string
+	Filepath string
+	Volume   float64       // 0-1
+	Fade     time.Duration // Time the bed takes to reach the volume.
 }
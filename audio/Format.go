@@ -0,0 +1,65 @@
+package audio
+
+import (
+	"bytes"
+
+	"github.com/chimera-rpg/go-server/network"
+)
+
+// soundFormat is the encoding of a sound file.
+type soundFormat int
+
+// Our supported sound formats.
+const (
+	formatUnknown soundFormat = iota
+	formatOgg
+	formatFlac
+	formatWav
+	formatMp3
+)
+
+// formatHeaderSize is the number of bytes needed to detect a sound format.
+const formatHeaderSize = 12
+
+func (f soundFormat) String() string {
+	switch f {
+	case formatOgg:
+		return "ogg"
+	case formatFlac:
+		return "flac"
+	case formatWav:
+		return "wav"
+	case formatMp3:
+		return "mp3"
+	}
+	return "unknown"
+}
+
+// formatFromType returns the format of the given network sound type.
+func formatFromType(t uint8) soundFormat {
+	switch t {
+	case network.SoundOgg:
+		return formatOgg
+	case network.SoundFlac:
+		return formatFlac
+	}
+	return formatUnknown
+}
+
+// detectFormat returns the format of a sound file from its first bytes.
+func detectFormat(header []byte) soundFormat {
+	switch {
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return formatOgg
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return formatFlac
+	case len(header) >= 12 && bytes.HasPrefix(header, []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WAVE")):
+		return formatWav
+	case bytes.HasPrefix(header, []byte("ID3")):
+		return formatMp3
+	case len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0:
+		// An MPEG audio frame sync without an ID3 tag.
+		return formatMp3
+	}
+	return formatUnknown
+}
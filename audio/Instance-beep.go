@@ -1,20 +1,33 @@
+//go:build !headless
+// +build !headless
+
 package audio
 
 import (
+	"github.com/faiface/beep"
 	"github.com/faiface/beep/speaker"
 	"github.com/sirupsen/logrus"
 )
 
+// speakerSampleRate is the sample rate all sounds are resampled to.
+const speakerSampleRate = beep.SampleRate(44100)
+
 func (instance *Instance) Setup(l *logrus.Logger) (err error) {
 	instance.CommandChannel = make(chan CommandI)
 	instance.QuitChannel = make(chan bool)
 	instance.sounds = make(map[uint32]*Sound)
-	instance.playingMusic = make(map[uint32]uint32)
+	instance.playingMusic = make(map[uint32]*SoundInstance)
+	instance.ambience = make(map[string]*ambienceBed)
+	instance.setVoiceLimits(CommandSetVoiceLimits{})
+	instance.mixer.init()
 	instance.log = l
 
-	err = speaker.Init(44100, 2048)
-	if err != nil {
-		return err
+	// Without a sound device, commands are still handled so that senders never block, but only recorded.
+	if err := speaker.Init(speakerSampleRate, 2048); err != nil {
+		instance.log.Warnln("[Audio] could not open sound device, falling back to the null backend:", err)
+		instance.null = true
+		instance.Record = true
+		return nil
 	}
 
 	instance.log.Infoln("[Audio] beep Initialized")
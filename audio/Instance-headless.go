@@ -0,0 +1,107 @@
+//go:build headless
+// +build headless
+
+package audio
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Setup sets up the null backend. It requires no sound device, plays nothing, and records every command in CommandLog.
+func (instance *Instance) Setup(l *logrus.Logger) (err error) {
+	instance.CommandChannel = make(chan CommandI)
+	instance.QuitChannel = make(chan bool)
+	instance.sounds = make(map[uint32]*Sound)
+	instance.playingMusic = make(map[uint32]*SoundInstance)
+	instance.ambience = make(map[string]*ambienceBed)
+	instance.setVoiceLimits(CommandSetVoiceLimits{})
+	instance.mixer.init()
+	instance.log = l
+	instance.Record = true
+
+	instance.log.Infoln("[Audio] null backend Initialized")
+
+	return nil
+}
+
+// Sound is a registered sound. The null backend never opens its file.
+type Sound struct {
+	filepath  string
+	soundType uint8
+}
+
+// SoundInstance is playing music. Music never ends on its own in the null backend, so queued music only starts once it is stopped.
+type SoundInstance struct {
+	loops bool // Whether the sound plays forever.
+}
+
+// voice is a playing sound effect. The null backend forgets sounds as soon as they are played.
+type voice struct{}
+
+func newSoundFromCommand(c CommandNewSound) *Sound {
+	return &Sound{
+		filepath:  c.Filepath,
+		soundType: c.Type,
+	}
+}
+
+func (s *Sound) release() {
+}
+
+func (instance *Instance) playSound(snd *Sound, c CommandPlaySound) {
+}
+
+func (instance *Instance) stopSound(soundID uint32) {
+}
+
+func (instance *Instance) refreshVoices() {
+}
+
+func (instance *Instance) moveListener(c CommandMoveListener) {
+	instance.listener = position{Y: c.Y, X: c.X, Z: c.Z}
+}
+
+func (instance *Instance) occludeSound(c CommandOccludeSound) {
+}
+
+func (instance *Instance) setVolume(c CommandSetVolume) {
+	instance.mixer.setVolume(c.Bus, c.Volume)
+}
+
+func (instance *Instance) setMute(c CommandSetMute) {
+	instance.mixer.setMute(c.Bus, c.Muted)
+}
+
+// playMusic marks the given music as playing unless its PlaybackID is already playing. Unlike the beep backend, the sound does not need to be registered.
+func (instance *Instance) playMusic(c CommandPlayMusic) {
+	if c.Crossfade {
+		instance.stopAllMusic(c.FadeIn, &c.PlaybackID)
+	}
+	if _, ok := instance.playingMusic[c.PlaybackID]; ok {
+		return
+	}
+	instance.playingMusic[c.PlaybackID] = &SoundInstance{loops: c.Loop < 0}
+}
+
+func (instance *Instance) stopMusic(playbackID uint32, fadeOut time.Duration) {
+	delete(instance.playingMusic, playbackID)
+}
+
+func (instance *Instance) stopAllMusic(fadeOut time.Duration, except *uint32) {
+	for playbackID := range instance.playingMusic {
+		if except != nil && playbackID == *except {
+			continue
+		}
+		instance.stopMusic(playbackID, fadeOut)
+	}
+}
+
+func (instance *Instance) setAmbience(c CommandSetAmbience) {
+	if c.Volume <= 0 {
+		delete(instance.ambience, c.Name)
+		return
+	}
+	instance.ambience[c.Name] = &ambienceBed{filepath: c.Filepath, si: &SoundInstance{loops: true}}
+}
@@ -0,0 +1,116 @@
+//go:build headless
+// +build headless
+
+package audio
+
+import (
+	"io"
+	"math"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// newTestInstance sets up a null backend instance with its Loop running.
+func newTestInstance(t *testing.T) *Instance {
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	instance := &Instance{}
+	if err := instance.Setup(l); err != nil {
+		t.Fatal(err)
+	}
+	go instance.Loop()
+	t.Cleanup(instance.Quit)
+	return instance
+}
+
+// send sends the given commands to the instance's Loop and returns the entries they were recorded as. A final CommandStopSound is sent so that the Loop has finished handling the others before the log is read, and is left out of the returned entries.
+func send(instance *Instance, cmds ...CommandI) (entries []CommandLogEntry) {
+	instance.ClearCommandLog()
+	for _, cmd := range cmds {
+		instance.CommandChannel <- cmd
+	}
+	instance.CommandChannel <- CommandStopSound{}
+	for _, entry := range instance.CommandLog() {
+		if entry.Command != (CommandStopSound{}) {
+			entries = append(entries, entry)
+		}
+	}
+	return
+}
+
+func TestLoopRecordsVolumes(t *testing.T) {
+	instance := newTestInstance(t)
+	send(instance, CommandNewSound{ID: 1, Filepath: "1.ogg"})
+
+	tests := []struct {
+		name   string
+		cmds   []CommandI
+		volume float64 // Volume of the last command.
+	}{
+		{
+			name:   "default",
+			cmds:   []CommandI{CommandPlaySound{ID: 1, Volume: 0.5}},
+			volume: 0.5,
+		},
+		{
+			name: "bus volume",
+			cmds: []CommandI{
+				CommandSetVolume{Bus: BusEffects, Volume: 0.5},
+				CommandPlaySound{ID: 1, Volume: 0.5},
+			},
+			volume: 0.25,
+		},
+		{
+			name: "master volume",
+			cmds: []CommandI{
+				CommandSetVolume{Bus: BusMaster, Volume: 0.5},
+				CommandPlaySound{ID: 1, Volume: 1, Bus: BusUI},
+			},
+			volume: 0.5,
+		},
+		{
+			name: "clamped volume",
+			cmds: []CommandI{
+				CommandSetVolume{Bus: BusMaster, Volume: 2},
+				CommandSetVolume{Bus: BusEffects, Volume: -1},
+				CommandPlaySound{ID: 1, Volume: 1},
+			},
+			volume: 0,
+		},
+		{
+			name: "muted bus",
+			cmds: []CommandI{
+				CommandSetVolume{Bus: BusEffects, Volume: 1},
+				CommandSetMute{Bus: BusEffects, Muted: true},
+				CommandPlaySound{ID: 1, Volume: 1},
+			},
+			volume: 0,
+		},
+		{
+			name: "positional",
+			cmds: []CommandI{
+				CommandSetMute{Bus: BusEffects, Muted: false},
+				CommandMoveListener{Y: 0, X: 0, Z: 0},
+				CommandPlaySound{ID: 1, Volume: 1, Positional: true, X: spatialFalloff, Occlusion: 1},
+			},
+			volume: 0.5 * occlusionGain,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := send(instance, tt.cmds...)
+			if len(log) != len(tt.cmds) {
+				t.Fatalf("got %d log entries, want %d", len(log), len(tt.cmds))
+			}
+			for i, entry := range log {
+				if entry.Command != tt.cmds[i] {
+					t.Errorf("entry %d is %+v, want %+v", i, entry.Command, tt.cmds[i])
+				}
+			}
+			if got := log[len(log)-1].Volume; math.Abs(got-tt.volume) > 1e-9 {
+				t.Errorf("got volume %.4f, want %.4f", got, tt.volume)
+			}
+		})
+	}
+}
@@ -1,68 +1,112 @@
 package audio
 
 import (
+	"sync"
+	"time"
+
 	"github.com/sirupsen/logrus"
 )
 
 // Instance is the managing instance of the Audio system.
 type Instance struct {
-	log            *logrus.Logger
-	sounds         map[uint32]*Sound
-	playingMusic   map[uint32]uint32
-	CommandChannel chan CommandI
-	QuitChannel    chan bool
+	log               *logrus.Logger
+	sounds            map[uint32]*Sound
+	playingMusic      map[uint32]*SoundInstance // Playing music by its PlaybackID.
+	musicQueue        []CommandPlayMusic        // Music waiting for the current music to end.
+	ambience          map[string]*ambienceBed   // Playing ambience beds by name.
+	listener          position                  // Position positional sounds are heard from.
+	voices            []*voice                  // Playing sound effects, from oldest to newest.
+	maxVoices         int
+	maxVoicesPerSound int
+	voiceInfo         []VoiceInfo
+	voiceInfoLock     sync.Mutex
+	mixer             mixer
+	commandLog        []CommandLogEntry
+	commandLogLock    sync.Mutex
+	SoundMemory       int64 // Bytes of decoded sounds to keep buffered before the least recently played are released. Set before Setup.
+	Record            bool  // Records every handled command in CommandLog. Always set by the null backend.
+	null              bool  // Set if no sound device could be opened, in which case commands are handled and recorded but nothing is played.
+	CommandChannel    chan CommandI
+	QuitChannel       chan bool
 }
 
+// DefaultSoundMemory is the memory budget in megabytes for buffered sounds used if none is set.
+const DefaultSoundMemory = 64
+
 // GlobalInstance is the reference to the instantiated Instance.
 var GlobalInstance *Instance
 
 // Loop is the loop for the audio instance.
 func (instance *Instance) Loop() {
+	ticker := time.NewTicker(voiceRefreshInterval)
+	defer ticker.Stop()
 	for {
 		select {
 		case <-instance.QuitChannel:
 			instance.log.Println("Quit")
 			// TODO: Cleanup.
 			return
+		case <-ticker.C:
+			instance.refreshVoices()
 		case cmd := <-instance.CommandChannel:
 			switch c := cmd.(type) {
 			case CommandNewSound:
-				if _, ok := instance.sounds[c.ID]; !ok {
+				// Sounds are replaced if they now come from a different file, such as after switching servers. Otherwise the file may have been rewritten, so its buffer is released.
+				if snd, ok := instance.sounds[c.ID]; !ok || snd.filepath != c.Filepath {
 					snd := newSoundFromCommand(c)
 					instance.sounds[c.ID] = snd
+				} else {
+					snd.release()
+				}
+			case CommandRemoveSound:
+				if snd, ok := instance.sounds[c.ID]; ok {
+					snd.release()
+					delete(instance.sounds, c.ID)
 				}
 			case CommandPlaySound:
 				if snd, ok := instance.sounds[c.ID]; ok {
-					snd.playAsSound(c.Volume)
+					instance.playSound(snd, c)
 				} else {
 					instance.log.Errorf("[Audio] missing sound %d", c.ID)
 				}
 			case CommandStopSound:
+				instance.stopSound(c.ID)
+			case CommandSetVoiceLimits:
+				instance.setVoiceLimits(c)
+			case CommandMoveListener:
+				instance.moveListener(c)
+			case CommandOccludeSound:
+				instance.occludeSound(c)
+			case CommandSetVolume:
+				instance.setVolume(c)
+			case CommandSetMute:
+				instance.setMute(c)
 			case CommandPlayMusic:
-				if snd, ok := instance.sounds[c.ID]; ok {
-					snd.playAsMusic(c.PlaybackID, c.Volume, 0)
-					instance.playingMusic[c.PlaybackID] = c.ID
-				} else {
-					instance.log.Errorf("[Audio] missing sound %d", c.ID)
-				}
+				instance.playMusic(c)
 			case CommandStopMusic:
-				if sndId, ok := instance.playingMusic[c.PlaybackID]; ok {
-					if snd, ok := instance.sounds[sndId]; ok {
-						snd.stopMusic(c.PlaybackID)
-						delete(instance.playingMusic, c.PlaybackID)
-					} else {
-						instance.log.Errorf("[Audio] missing sound %d", sndId)
-					}
+				if _, ok := instance.playingMusic[c.PlaybackID]; ok {
+					instance.stopMusic(c.PlaybackID, c.FadeOut)
 				} else {
 					instance.log.Errorf("[Audio] missing music playback %d", c.PlaybackID)
 				}
 			case CommandStopAllMusic:
-				for playbackID, sndId := range instance.playingMusic {
-					if snd, ok := instance.sounds[sndId]; ok {
-						snd.stopMusic(playbackID)
-					}
+				instance.musicQueue = nil
+				instance.stopAllMusic(c.FadeOut, nil)
+			case CommandQueueMusic:
+				instance.musicQueue = append(instance.musicQueue, c.CommandPlayMusic)
+				instance.advanceMusicQueue()
+			case CommandClearMusicQueue:
+				instance.musicQueue = nil
+			case CommandSetAmbience:
+				instance.setAmbience(c)
+			case musicEnded:
+				if si, ok := instance.playingMusic[c.playbackID]; ok && si == c.instance {
+					delete(instance.playingMusic, c.playbackID)
 				}
-				instance.playingMusic = make(map[uint32]uint32)
+				instance.advanceMusicQueue()
+			}
+			if instance.Record {
+				instance.record(cmd)
 			}
 		}
 	}
@@ -0,0 +1,63 @@
+package audio
+
+import (
+	"fmt"
+	"time"
+)
+
+// CommandLogEntry is a command handled by the Loop while Record is set.
+type CommandLogEntry struct {
+	Time    time.Time
+	Command CommandI
+	Volume  float64 // Volume the command plays at after its bus and position are applied. Zero for commands that play nothing.
+}
+
+func (e CommandLogEntry) String() string {
+	return fmt.Sprintf("%s %T %+v %.2f", e.Time.Format("15:04:05.000"), e.Command, e.Command, e.Volume)
+}
+
+// CommandLog returns every command recorded so far, from oldest to newest. It is safe to call from other goroutines.
+func (instance *Instance) CommandLog() []CommandLogEntry {
+	instance.commandLogLock.Lock()
+	defer instance.commandLogLock.Unlock()
+	return append([]CommandLogEntry(nil), instance.commandLog...)
+}
+
+// ClearCommandLog forgets every recorded command. It is safe to call from other goroutines.
+func (instance *Instance) ClearCommandLog() {
+	instance.commandLogLock.Lock()
+	defer instance.commandLogLock.Unlock()
+	instance.commandLog = nil
+}
+
+// record adds a handled command to the command log.
+func (instance *Instance) record(cmd CommandI) {
+	entry := CommandLogEntry{
+		Time:    time.Now(),
+		Command: cmd,
+		Volume:  instance.commandVolume(cmd),
+	}
+	instance.commandLogLock.Lock()
+	defer instance.commandLogLock.Unlock()
+	instance.commandLog = append(instance.commandLog, entry)
+}
+
+// commandVolume returns the volume the given command plays at with the current mixer and listener.
+func (instance *Instance) commandVolume(cmd CommandI) float64 {
+	switch c := cmd.(type) {
+	case CommandPlaySound:
+		volume := float64(c.Volume) * instance.mixer.bus(c.Bus).gain
+		if c.Positional {
+			gain, _, _ := spatialize(instance.listener, position{Y: c.Y, X: c.X, Z: c.Z}, c.Occlusion)
+			volume *= gain
+		}
+		return volume
+	case CommandPlayMusic:
+		return float64(c.Volume) * instance.mixer.bus(BusMusic).gain
+	case CommandQueueMusic:
+		return float64(c.Volume) * instance.mixer.bus(BusMusic).gain
+	case CommandSetAmbience:
+		return c.Volume * instance.mixer.bus(BusAmbience).gain
+	}
+	return 0
+}
@@ -0,0 +1,46 @@
+//go:build !headless
+// +build !headless
+
+package audio
+
+import (
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// busStreamer scales its Streamer by its Volume and the gain of the bus it plays through. Volume must only be changed while the speaker is locked.
+type busStreamer struct {
+	Streamer beep.Streamer
+	Volume   float64
+	bus      *busState
+}
+
+// Stream streams the wrapped Streamer scaled by the volume and bus gain.
+func (s *busStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = s.Streamer.Stream(samples)
+	gain := s.Volume * s.bus.gain
+	for i := range samples[:n] {
+		samples[i][0] *= gain
+		samples[i][1] *= gain
+	}
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (s *busStreamer) Err() error {
+	return s.Streamer.Err()
+}
+
+// setVolume sets the volume of the given bus.
+func (instance *Instance) setVolume(c CommandSetVolume) {
+	speaker.Lock()
+	defer speaker.Unlock()
+	instance.mixer.setVolume(c.Bus, c.Volume)
+}
+
+// setMute mutes or unmutes the given bus.
+func (instance *Instance) setMute(c CommandSetMute) {
+	speaker.Lock()
+	defer speaker.Unlock()
+	instance.mixer.setMute(c.Bus, c.Muted)
+}
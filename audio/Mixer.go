@@ -0,0 +1,112 @@
+package audio
+
+import (
+	"github.com/chimera-rpg/go-client/config"
+)
+
+// Bus is a mixer bus that sounds are played through. Every bus is in turn scaled by BusMaster.
+type Bus uint8
+
+// Our mixer buses. BusEffects is the zero value so that sounds play as effects unless told otherwise.
+const (
+	BusEffects Bus = iota
+	BusMusic
+	BusUI
+	BusAmbience
+	BusMaster
+	busCount
+)
+
+// Buses lists every mixer bus.
+var Buses = []Bus{BusMaster, BusMusic, BusEffects, BusUI, BusAmbience}
+
+func (b Bus) String() string {
+	switch b {
+	case BusEffects:
+		return "effects"
+	case BusMusic:
+		return "music"
+	case BusUI:
+		return "ui"
+	case BusAmbience:
+		return "ambience"
+	case BusMaster:
+		return "master"
+	}
+	return "unknown"
+}
+
+// BusConfig returns the configuration of the given bus.
+func BusConfig(c *config.AudioConfig, bus Bus) *config.BusConfig {
+	switch bus {
+	case BusMusic:
+		return &c.Music
+	case BusUI:
+		return &c.UI
+	case BusAmbience:
+		return &c.Ambience
+	case BusMaster:
+		return &c.Master
+	}
+	return &c.Effects
+}
+
+// busState is the volume and mute state of a single bus.
+type busState struct {
+	volume float64
+	muted  bool
+	gain   float64 // Resulting gain, including the master bus.
+}
+
+// mixer holds the state of every bus.
+type mixer struct {
+	buses [busCount]busState
+}
+
+func (m *mixer) init() {
+	for i := range m.buses {
+		m.buses[i].volume = 1
+	}
+	m.refresh()
+}
+
+// bus returns the state of the given bus, falling back to BusEffects for unknown buses.
+func (m *mixer) bus(b Bus) *busState {
+	if b >= busCount {
+		b = BusEffects
+	}
+	return &m.buses[b]
+}
+
+func (m *mixer) setVolume(b Bus, volume float64) {
+	if volume < 0 {
+		volume = 0
+	} else if volume > 1 {
+		volume = 1
+	}
+	m.bus(b).volume = volume
+	m.refresh()
+}
+
+func (m *mixer) setMute(b Bus, muted bool) {
+	m.bus(b).muted = muted
+	m.refresh()
+}
+
+// refresh recalculates the resulting gain of every bus.
+func (m *mixer) refresh() {
+	master := m.buses[BusMaster].volume
+	if m.buses[BusMaster].muted {
+		master = 0
+	}
+	for i := range m.buses {
+		b := &m.buses[i]
+		b.gain = b.volume
+		if b.muted {
+			b.gain = 0
+		}
+		if Bus(i) != BusMaster {
+			b.gain *= master
+		}
+	}
+}
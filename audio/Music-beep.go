@@ -0,0 +1,126 @@
+//go:build !headless
+// +build !headless
+
+package audio
+
+import (
+	"math"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// fader fades its Streamer towards a target gain. Once faded out while stopping, the stream ends. Its state must only be changed while the speaker is locked.
+type fader struct {
+	Streamer beep.Streamer
+	gain     float64
+	target   float64
+	step     float64 // Change in gain per sample.
+	stopping bool
+}
+
+// newFader returns a fader that fades in its Streamer over the given duration.
+func newFader(streamer beep.Streamer, fadeIn time.Duration) *fader {
+	f := &fader{
+		Streamer: streamer,
+		gain:     1,
+		target:   1,
+	}
+	if fadeIn > 0 {
+		f.gain = 0
+		f.fadeTo(1, fadeIn)
+	}
+	return f
+}
+
+// fadeTo fades towards the given gain over the given duration.
+func (f *fader) fadeTo(target float64, d time.Duration) {
+	f.target = target
+	samples := speakerSampleRate.N(d)
+	if samples <= 0 {
+		f.gain = target
+		return
+	}
+	f.step = math.Abs(target-f.gain) / float64(samples)
+}
+
+// fadeOut fades out over the given duration and then ends the stream.
+func (f *fader) fadeOut(d time.Duration) {
+	f.stopping = true
+	f.fadeTo(0, d)
+}
+
+// Stream streams the wrapped Streamer at the current gain.
+func (f *fader) Stream(samples [][2]float64) (n int, ok bool) {
+	if f.stopping && f.gain <= 0 {
+		return 0, false
+	}
+	n, ok = f.Streamer.Stream(samples)
+	for i := range samples[:n] {
+		if f.gain < f.target {
+			f.gain = math.Min(f.target, f.gain+f.step)
+		} else if f.gain > f.target {
+			f.gain = math.Max(f.target, f.gain-f.step)
+		}
+		samples[i][0] *= f.gain
+		samples[i][1] *= f.gain
+	}
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (f *fader) Err() error {
+	return f.Streamer.Err()
+}
+
+// playMusic starts playing the given music unless its PlaybackID is already playing.
+func (instance *Instance) playMusic(c CommandPlayMusic) {
+	if instance.null {
+		return
+	}
+	snd, ok := instance.sounds[c.ID]
+	if !ok {
+		instance.log.Errorf("[Audio] missing sound %d", c.ID)
+		return
+	}
+	if c.Crossfade {
+		instance.stopAllMusic(c.FadeIn, &c.PlaybackID)
+	}
+	if _, ok := instance.playingMusic[c.PlaybackID]; ok {
+		return
+	}
+	si, err := snd.playAsMusic(c.Volume, c.Loop, c.FadeIn, instance.mixer.bus(BusMusic), func(si *SoundInstance) {
+		// This is called from within the speaker, so we must not block it.
+		go func() {
+			instance.CommandChannel <- musicEnded{playbackID: c.PlaybackID, instance: si}
+		}()
+	})
+	if err != nil {
+		instance.log.Errorf("[Audio] could not play music %d: %s", c.ID, err)
+		return
+	}
+	instance.playingMusic[c.PlaybackID] = si
+}
+
+// stopMusic fades out and stops the given music playback.
+func (instance *Instance) stopMusic(playbackID uint32, fadeOut time.Duration) {
+	si, ok := instance.playingMusic[playbackID]
+	if !ok {
+		return
+	}
+	speaker.Lock()
+	si.fader.fadeOut(fadeOut)
+	speaker.Unlock()
+	delete(instance.playingMusic, playbackID)
+}
+
+// stopAllMusic fades out and stops all music playbacks except the one matching except, if given.
+func (instance *Instance) stopAllMusic(fadeOut time.Duration, except *uint32) {
+	for playbackID := range instance.playingMusic {
+		if except != nil && playbackID == *except {
+			continue
+		}
+		instance.stopMusic(playbackID, fadeOut)
+	}
+}
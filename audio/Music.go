@@ -0,0 +1,26 @@
+package audio
+
+// musicEnded is sent by the backend once music has finished playing or has faded out.
+type musicEnded struct {
+	playbackID uint32
+	instance   *SoundInstance
+}
+
+// advanceMusicQueue plays the next queued music if no music is playing or if all playing music plays forever.
+func (instance *Instance) advanceMusicQueue() {
+	for _, si := range instance.playingMusic {
+		if !si.loops {
+			return
+		}
+	}
+	// Skip over any music that fails to play.
+	for len(instance.musicQueue) > 0 {
+		next := instance.musicQueue[0]
+		instance.musicQueue = instance.musicQueue[1:]
+		next.Crossfade = true
+		instance.playMusic(next)
+		if _, ok := instance.playingMusic[next.PlaybackID]; ok {
+			return
+		}
+	}
+}
@@ -1,113 +1,208 @@
+//go:build !headless
+// +build !headless
+
 package audio
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"os"
+	"sort"
+	"time"
 
-	"github.com/chimera-rpg/go-server/network"
 	"github.com/faiface/beep"
-	"github.com/faiface/beep/effects"
 	"github.com/faiface/beep/flac"
+	"github.com/faiface/beep/mp3"
 	"github.com/faiface/beep/speaker"
 	"github.com/faiface/beep/vorbis"
+	"github.com/faiface/beep/wav"
 )
 
+// Sound is a registered sound. Sounds played as effects are decoded and buffered on first play, while music is streamed from disk.
 type Sound struct {
 	filepath    string
 	soundType   uint8
-	format      beep.Format
-	streamer    beep.StreamSeekCloser
 	soundBuffer *beep.Buffer
-	instances   map[uint32]*SoundInstance
+	bufferSize  int64     // Approximate memory used by soundBuffer.
+	lastPlayed  time.Time // Last time the buffer was played.
+	decodeErr   error     // Set if the sound could not be decoded, so that it is not retried on every play.
+	reported    bool      // Whether decodeErr has been logged.
 }
 
 type SoundInstance struct {
 	control   *beep.Ctrl
-	volume    *effects.Volume
+	volume    *busStreamer
 	resampler *beep.Resampler
+	fader     *fader
+	loops     bool // Whether the sound plays forever.
 }
 
 func newSoundFromCommand(c CommandNewSound) *Sound {
-	s := Sound{
-		instances: make(map[uint32]*SoundInstance),
+	return &Sound{
 		filepath:  c.Filepath,
 		soundType: c.Type,
 	}
+}
 
-	streamer, format, err := s.decode()
+// decode opens the sound's file for decoding. The format is detected from the file's first bytes if they do not match the declared type. The returned streamer must be closed to release the file.
+func (s *Sound) decode() (beep.StreamSeekCloser, beep.Format, error) {
+	f, err := os.Open(s.filepath)
 	if err != nil {
-		return &s
+		return nil, beep.Format{}, err
+	}
+	header := make([]byte, formatHeaderSize)
+	n, _ := io.ReadFull(f, header)
+	format := formatFromType(s.soundType)
+	if detected := detectFormat(header[:n]); detected != formatUnknown {
+		format = detected
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, beep.Format{}, err
 	}
-	s.streamer = streamer
-	s.format = format
 
-	return &s
+	var streamer beep.StreamSeekCloser
+	var bf beep.Format
+	switch format {
+	case formatOgg:
+		streamer, bf, err = vorbis.Decode(f)
+	case formatFlac:
+		streamer, bf, err = flac.Decode(f)
+	case formatWav:
+		streamer, bf, err = wav.Decode(f)
+	case formatMp3:
+		streamer, bf, err = mp3.Decode(f)
+	default:
+		f.Close()
+		return nil, beep.Format{}, errors.New("unsupported file")
+	}
+	if err != nil {
+		f.Close()
+		return nil, beep.Format{}, fmt.Errorf("decoding %s: %w", format, err)
+	}
+	return streamer, bf, nil
 }
 
-func (s *Sound) decode() (beep.StreamSeekCloser, beep.Format, error) {
-	f, err := os.Open(s.filepath)
+// buffer decodes the whole sound into memory at the speaker's sample rate.
+func (s *Sound) buffer() error {
+	if s.decodeErr != nil {
+		return s.decodeErr
+	}
+	streamer, format, err := s.decode()
 	if err != nil {
-		return nil, beep.Format{}, err
+		s.decodeErr = err
+		return err
 	}
-	if s.soundType == network.SoundFlac {
-		streamer, format, err := flac.Decode(f)
-		if err != nil {
-			return nil, beep.Format{}, err
-		}
-		return streamer, format, nil
-	} else if s.soundType == network.SoundOgg {
-		streamer, format, err := vorbis.Decode(f)
-		if err != nil {
-			return nil, beep.Format{}, err
-		}
-		return streamer, format, nil
+	defer streamer.Close()
+
+	resampler := beep.Resample(4, format.SampleRate, speakerSampleRate, streamer)
+	format.SampleRate = speakerSampleRate
+	s.soundBuffer = beep.NewBuffer(format)
+	s.soundBuffer.Append(resampler)
+	if err := streamer.Err(); err != nil {
+		s.soundBuffer = nil
+		s.decodeErr = err
+		return err
 	}
-	return nil, beep.Format{}, errors.New("unsupported file")
+	s.bufferSize = int64(s.soundBuffer.Len() * format.Width())
+	return nil
+}
+
+// release releases the sound's buffer and forgets any decode error. Voices that are still playing keep their own reference to the buffer.
+func (s *Sound) release() {
+	s.soundBuffer = nil
+	s.bufferSize = 0
+	s.decodeErr = nil
+	s.reported = false
 }
 
-func (s *Sound) playAsSound(volume float32) {
+// soundStreamer returns a new streamer over the buffered sound, buffering it if needed.
+func (s *Sound) soundStreamer() (beep.Streamer, error) {
 	if s.soundBuffer == nil {
-		resampler := beep.Resample(4, s.format.SampleRate, beep.SampleRate(44100), s.streamer)
-		s.soundBuffer = beep.NewBuffer(s.format)
-		s.soundBuffer.Append(resampler)
+		if err := s.buffer(); err != nil {
+			return nil, err
+		}
 	}
-	sound := s.soundBuffer.Streamer(0, s.soundBuffer.Len())
-	vol := &effects.Volume{Streamer: sound, Base: float64(volume)}
-	speaker.Play(vol)
+	s.lastPlayed = time.Now()
+	return s.soundBuffer.Streamer(0, s.soundBuffer.Len()), nil
 }
 
-func (s *Sound) playAsMusic(id uint32, volume float32, loop int) *SoundInstance {
-	if si, ok := s.instances[id]; ok {
-		return si
+// playAsVoice plays the sound as the given voice, positioning it if it has a spatialStreamer. The voice is marked as done once it has finished.
+func (s *Sound) playAsVoice(v *voice, bus *busState) error {
+	streamer, err := s.soundStreamer()
+	if err != nil {
+		return err
+	}
+	volume := v.volume
+	if v.spatial != nil {
+		// The spatialStreamer's gain already includes the volume.
+		v.spatial.Streamer = streamer
+		streamer = v.spatial
+		volume = 1
+	}
+	v.ctrl = &beep.Ctrl{
+		Streamer: &busStreamer{Streamer: streamer, Volume: volume, bus: bus},
 	}
+	speaker.Play(beep.Seq(v.ctrl, beep.Callback(func() {
+		v.done = true
+	})))
+	return nil
+}
 
+// playAsMusic streams the sound from disk as music, calling onEnd once it has finished or faded out.
+func (s *Sound) playAsMusic(volume float32, loop int, fadeIn time.Duration, bus *busState, onEnd func(si *SoundInstance)) (*SoundInstance, error) {
 	streamer, format, err := s.decode()
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
 	if loop == 0 {
-		loop = -1
+		loop = 1
+	}
+	si := &SoundInstance{
+		loops: loop < 0,
 	}
-	si := &SoundInstance{}
 	si.control = &beep.Ctrl{
 		Streamer: beep.Loop(loop, streamer),
 	}
-	si.resampler = beep.Resample(4, format.SampleRate, beep.SampleRate(44100), si.control)
-	si.volume = &effects.Volume{Streamer: si.resampler, Base: float64(volume)}
+	si.resampler = beep.Resample(4, format.SampleRate, speakerSampleRate, si.control)
+	si.volume = &busStreamer{Streamer: si.resampler, Volume: float64(volume), bus: bus}
+	si.fader = newFader(si.volume, fadeIn)
 
-	s.instances[id] = si
+	speaker.Play(beep.Seq(si.fader, beep.Callback(func() {
+		streamer.Close()
+		onEnd(si)
+	})))
 
-	speaker.Play(si.volume)
-
-	return si
+	return si, nil
 }
 
-func (s *Sound) stopMusic(id uint32) {
-	si, ok := s.instances[id]
-	if !ok {
+// releaseSoundBuffers releases the buffers of the least recently played sounds until they fit within SoundMemory.
+func (instance *Instance) releaseSoundBuffers() {
+	budget := instance.SoundMemory
+	if budget <= 0 {
+		budget = DefaultSoundMemory * 1024 * 1024
+	}
+	var total int64
+	var buffered []*Sound
+	for _, snd := range instance.sounds {
+		if snd.soundBuffer != nil {
+			total += snd.bufferSize
+			buffered = append(buffered, snd)
+		}
+	}
+	if total <= budget {
 		return
 	}
-	si.control.Paused = true
-	delete(s.instances, id)
+	sort.Slice(buffered, func(i, j int) bool {
+		return buffered[i].lastPlayed.Before(buffered[j].lastPlayed)
+	})
+	for _, snd := range buffered {
+		if total <= budget {
+			break
+		}
+		total -= snd.bufferSize
+		snd.release()
+	}
 }
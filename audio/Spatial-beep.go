@@ -0,0 +1,92 @@
+//go:build !headless
+// +build !headless
+
+package audio
+
+import (
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// spatialStreamer pans, attenuates, and muffles its Streamer. Its fields must only be changed while the speaker is locked.
+type spatialStreamer struct {
+	Streamer    beep.Streamer
+	Gain        float64
+	Pan         float64 // -1 to 1
+	Muffle      float64 // 0 to 1
+	lowL, lowR  float64 // Low-pass filter state.
+	curGain     float64
+	curPan      float64
+	initialized bool
+}
+
+// spatialSmoothing is how quickly the applied gain and pan approach their targets per sample, avoiding clicks when the listener moves.
+const spatialSmoothing = 0.001
+
+// Stream streams the wrapped Streamer, applying a low-pass filter for muffling and then gain and pan.
+func (s *spatialStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = s.Streamer.Stream(samples)
+	if !s.initialized {
+		s.curGain = s.Gain
+		s.curPan = s.Pan
+		s.initialized = true
+	}
+	// A one-pole low-pass filter where 1 lets everything through.
+	alpha := 1 - s.Muffle*0.95
+	for i := range samples[:n] {
+		s.curGain += (s.Gain - s.curGain) * spatialSmoothing
+		s.curPan += (s.Pan - s.curPan) * spatialSmoothing
+
+		s.lowL += alpha * (samples[i][0] - s.lowL)
+		s.lowR += alpha * (samples[i][1] - s.lowR)
+		l, r := s.lowL, s.lowR
+		// Move the opposite channel over as the sound pans to one side.
+		if s.curPan < 0 {
+			l += -s.curPan * r
+			r -= -s.curPan * r
+		} else if s.curPan > 0 {
+			r += s.curPan * l
+			l -= s.curPan * l
+		}
+		samples[i][0] = l * s.curGain
+		samples[i][1] = r * s.curGain
+	}
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (s *spatialStreamer) Err() error {
+	return s.Streamer.Err()
+}
+
+// moveListener moves the listener and updates all playing positional sounds.
+func (instance *Instance) moveListener(c CommandMoveListener) {
+	instance.listener = position{Y: c.Y, X: c.X, Z: c.Z}
+	speaker.Lock()
+	defer speaker.Unlock()
+	for _, v := range instance.voices {
+		if v.spatial != nil {
+			instance.respatialize(v)
+		}
+	}
+}
+
+// occludeSound updates the occlusion of a playing positional sound.
+func (instance *Instance) occludeSound(c CommandOccludeSound) {
+	speaker.Lock()
+	defer speaker.Unlock()
+	for _, v := range instance.voices {
+		if v.id == c.VoiceID && v.spatial != nil {
+			v.occlusion = c.Occlusion
+			instance.respatialize(v)
+		}
+	}
+}
+
+// respatialize updates the voice's streamer from its position relative to the listener. The speaker must be locked if the voice is playing.
+func (instance *Instance) respatialize(v *voice) {
+	gain, pan, muffle := spatialize(instance.listener, v.source, v.occlusion)
+	v.spatial.Gain = gain * v.volume
+	v.spatial.Pan = pan
+	v.spatial.Muffle = muffle
+}
@@ -0,0 +1,34 @@
+package audio
+
+import "math"
+
+const (
+	spatialFalloff  = 6.0 // Distance in tiles at which a positional sound is heard at half volume.
+	spatialPanWidth = 8.0 // Horizontal distance in tiles at which a positional sound is fully panned to one side.
+	occlusionGain   = 0.6 // Volume kept for each opaque tile between a positional sound and the listener.
+	occlusionMuffle = 0.5 // Portion of high frequencies removed for each opaque tile between a positional sound and the listener.
+	maxOcclusion    = 4   // Opaque tiles beyond this no longer muffle a sound further.
+)
+
+// position is a location in the world.
+type position struct {
+	Y, X, Z float64
+}
+
+// spatialize returns the gain, pan, and muffling of a sound at source as heard from listener, with occlusion opaque tiles between them. Pan goes from -1 for the left to 1 for the right, while muffle goes from 0 for none to 1 for fully muffled.
+func spatialize(listener, source position, occlusion int) (gain, pan, muffle float64) {
+	dy := source.Y - listener.Y
+	dx := source.X - listener.X
+	dz := source.Z - listener.Z
+	distance := math.Sqrt(dy*dy + dx*dx + dz*dz)
+
+	gain = 1 / (1 + distance/spatialFalloff)
+	pan = math.Max(-1, math.Min(1, dx/spatialPanWidth))
+
+	if occlusion > maxOcclusion {
+		occlusion = maxOcclusion
+	}
+	gain *= math.Pow(occlusionGain, float64(occlusion))
+	muffle = 1 - math.Pow(1-occlusionMuffle, float64(occlusion))
+	return
+}
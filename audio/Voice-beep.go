@@ -0,0 +1,151 @@
+//go:build !headless
+// +build !headless
+
+package audio
+
+import (
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// voice is a playing sound effect. Its done, ctrl, and spatial fields must only be accessed while the speaker is locked.
+type voice struct {
+	id        uint32 // VoiceID of a positional sound.
+	soundID   uint32
+	bus       Bus
+	started   time.Time
+	volume    float64
+	source    position
+	occlusion int
+	spatial   *spatialStreamer // Only set for positional sounds.
+	ctrl      *beep.Ctrl
+	done      bool // Set once the voice has finished playing.
+}
+
+// gain returns the current gain of the voice before its bus is applied.
+func (v *voice) gain() float64 {
+	if v.spatial != nil {
+		return v.spatial.Gain
+	}
+	return v.volume
+}
+
+// playSound plays the given sound as a new voice, first stopping other voices if it would exceed the voice limits.
+func (instance *Instance) playSound(snd *Sound, c CommandPlaySound) {
+	if instance.null {
+		return
+	}
+	v := &voice{
+		id:      c.VoiceID,
+		soundID: c.ID,
+		bus:     c.Bus,
+		started: time.Now(),
+		volume:  float64(c.Volume),
+	}
+	if c.Positional {
+		v.source = position{Y: c.Y, X: c.X, Z: c.Z}
+		v.occlusion = c.Occlusion
+		v.spatial = &spatialStreamer{}
+		instance.respatialize(v)
+	}
+
+	speaker.Lock()
+	instance.pruneVoices()
+	for instance.countVoices(c.ID) >= instance.maxVoicesPerSound {
+		if !instance.dropVoice(func(o *voice) bool { return o.soundID == c.ID }) {
+			break
+		}
+	}
+	for len(instance.voices) >= instance.maxVoices {
+		if !instance.dropVoice(nil) {
+			break
+		}
+	}
+	speaker.Unlock()
+
+	if err := snd.playAsVoice(v, instance.mixer.bus(c.Bus)); err != nil {
+		// Broken sounds are only reported once, as they may be played often.
+		if !snd.reported {
+			instance.log.Errorf("[Audio] could not play sound %d: %s", c.ID, err)
+			snd.reported = true
+		}
+		return
+	}
+	instance.voices = append(instance.voices, v)
+	instance.releaseSoundBuffers()
+	instance.refreshVoices()
+}
+
+// stopSound stops all voices of the given sound.
+func (instance *Instance) stopSound(soundID uint32) {
+	speaker.Lock()
+	for _, v := range instance.voices {
+		if v.soundID == soundID {
+			v.ctrl.Streamer = nil
+			v.done = true
+		}
+	}
+	speaker.Unlock()
+	instance.refreshVoices()
+}
+
+// countVoices returns the number of voices playing the given sound. The speaker must be locked.
+func (instance *Instance) countVoices(soundID uint32) (count int) {
+	for _, v := range instance.voices {
+		if v.soundID == soundID {
+			count++
+		}
+	}
+	return
+}
+
+// dropVoice stops the quietest voice that match accepts, or the oldest among equally quiet voices. A nil match accepts every voice. It returns false if no voice was stopped. The speaker must be locked.
+func (instance *Instance) dropVoice(match func(v *voice) bool) bool {
+	victim := -1
+	for i, v := range instance.voices {
+		if match != nil && !match(v) {
+			continue
+		}
+		// Voices are ordered from oldest to newest, so the oldest wins ties.
+		if victim == -1 || v.gain() < instance.voices[victim].gain() {
+			victim = i
+		}
+	}
+	if victim == -1 {
+		return false
+	}
+	instance.voices[victim].ctrl.Streamer = nil
+	instance.voices = append(instance.voices[:victim], instance.voices[victim+1:]...)
+	return true
+}
+
+// pruneVoices forgets voices that have finished playing. The speaker must be locked.
+func (instance *Instance) pruneVoices() {
+	voices := instance.voices[:0]
+	for _, v := range instance.voices {
+		if !v.done {
+			voices = append(voices, v)
+		}
+	}
+	instance.voices = voices
+}
+
+// refreshVoices forgets finished voices and refreshes the readout returned by Voices.
+func (instance *Instance) refreshVoices() {
+	speaker.Lock()
+	instance.pruneVoices()
+	info := make([]VoiceInfo, 0, len(instance.voices))
+	for _, v := range instance.voices {
+		info = append(info, VoiceInfo{
+			SoundID:    v.soundID,
+			Bus:        v.bus,
+			Positional: v.spatial != nil,
+			Gain:       v.gain(),
+			Started:    v.started,
+		})
+	}
+	speaker.Unlock()
+	instance.setVoiceInfo(info)
+}
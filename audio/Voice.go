@@ -0,0 +1,59 @@
+package audio
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultMaxVoices is the number of sounds that may play at once if none is configured.
+	DefaultMaxVoices = 32
+	// DefaultMaxVoicesPerSound is the number of times a single sound may play at once if none is configured.
+	DefaultMaxVoicesPerSound = 4
+)
+
+// voiceRefreshInterval is how often finished voices are forgotten and the voice readout is refreshed.
+const voiceRefreshInterval = 250 * time.Millisecond
+
+// VoiceInfo describes a playing sound for debugging.
+type VoiceInfo struct {
+	SoundID    uint32
+	Bus        Bus
+	Positional bool
+	Gain       float64 // Gain before the bus is applied.
+	Started    time.Time
+}
+
+func (v VoiceInfo) String() string {
+	kind := "flat"
+	if v.Positional {
+		kind = "positional"
+	}
+	return fmt.Sprintf("%d %s %s %.2f %.1fs", v.SoundID, v.Bus, kind, v.Gain, time.Since(v.Started).Seconds())
+}
+
+// Voices returns the currently playing sounds. It is safe to call from other goroutines.
+func (instance *Instance) Voices() []VoiceInfo {
+	instance.voiceInfoLock.Lock()
+	defer instance.voiceInfoLock.Unlock()
+	return append([]VoiceInfo(nil), instance.voiceInfo...)
+}
+
+// setVoiceInfo replaces the readout returned by Voices.
+func (instance *Instance) setVoiceInfo(info []VoiceInfo) {
+	instance.voiceInfoLock.Lock()
+	defer instance.voiceInfoLock.Unlock()
+	instance.voiceInfo = info
+}
+
+// setVoiceLimits sets the voice limits, using the defaults for any that are not positive.
+func (instance *Instance) setVoiceLimits(c CommandSetVoiceLimits) {
+	instance.maxVoices = c.Total
+	if instance.maxVoices <= 0 {
+		instance.maxVoices = DefaultMaxVoices
+	}
+	instance.maxVoicesPerSound = c.PerSound
+	if instance.maxVoicesPerSound <= 0 {
+		instance.maxVoicesPerSound = DefaultMaxVoicesPerSound
+	}
+}
@@ -1,7 +1,12 @@
 package client
 
 import (
+	"crypto/tls"
+	"encoding/gob"
 	"fmt"
+	"net"
+	"os"
+	"time"
 
 	"github.com/chimera-rpg/go-client/audio"
 	"github.com/chimera-rpg/go-client/config"
@@ -28,6 +33,9 @@ type Client struct {
 	RenderChannel    chan struct{}
 	StateChannel     chan StateMessage
 	AnimationsConfig clientAnimationsConfig
+	Recorder         *Recorder // Recorder for the current session, if Flags.Record is set.
+	Replaying        bool      // Whether commands are being replayed from a session file. Sent commands are dropped while replaying.
+	ExitCode         int       // Code to exit the program with once the UI loop stops.
 	// TODO: Probably move this elsewhere.
 	TypeHints map[uint32]string
 	Slots     map[uint32]string
@@ -41,7 +49,7 @@ func (c *Client) Setup(dataManager *data.Manager, inst *ui.Instance, aud *audio.
 	c.Audio = aud
 	c.UI = inst
 	c.DataManager = dataManager
-	c.DataManager.Conn = &c.Connection
+	c.DataManager.Conn = c
 
 	network.RegisterCommands()
 
@@ -51,8 +59,6 @@ func (c *Client) Setup(dataManager *data.Manager, inst *ui.Instance, aud *audio.
 	c.TypeHints = make(map[uint32]string)
 	c.Slots = make(map[uint32]string)
 
-	c.Flags.Parse()
-
 	c.isRunning = true
 	return
 }
@@ -65,6 +71,117 @@ func (c *Client) Destroy() {
 	c.DataManager.Config.Write()
 }
 
+// ConnectTo connects to the given address. If recording is enabled, the session is recorded to the cache directory.
+func (c *Client) ConnectTo(address string) (err error) {
+	if !c.Flags.Record {
+		return c.Connection.ConnectTo(address)
+	}
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		return
+	}
+	return c.startRecordedConnection(conn)
+}
+
+// SecureConnectTo functions as per ConnectTo but with an additional tls.Config argument.
+func (c *Client) SecureConnectTo(address string, conf *tls.Config) (err error) {
+	if !c.Flags.Record {
+		return c.Connection.SecureConnectTo(address, conf)
+	}
+	conn, err := tls.Dial("tcp", address, conf)
+	if err != nil {
+		return
+	}
+	return c.startRecordedConnection(conn)
+}
+
+// startRecordedConnection sets up the connection the same as network.Connection does, but with a command loop that records all received commands.
+func (c *Client) startRecordedConnection(conn net.Conn) (err error) {
+	filepath := c.DataManager.GetCachePath("sessions", time.Now().Format("2006-01-02_15-04-05")+".session")
+	if c.Recorder, err = NewRecorder(filepath, c.CurrentServer); err != nil {
+		conn.Close()
+		return
+	}
+	c.Log.Printf("Recording session to %s", filepath)
+
+	c.Conn = conn
+	c.Encoder = gob.NewEncoder(conn)
+	c.Decoder = gob.NewDecoder(conn)
+	c.CmdChan = make(chan network.Command)
+	c.ClosedChan = make(chan struct{})
+	c.IsConnected = true
+	go c.loopRecordedCmd()
+	return
+}
+
+// loopRecordedCmd is the same as network.Connection.LoopCmd, but records each command before pumping it into the CmdChan.
+func (c *Client) loopRecordedCmd() {
+	recorder := c.Recorder
+	defer recorder.Close()
+	var cmd network.Command
+	for c.IsConnected {
+		if err := c.Receive(&cmd); err != nil {
+			c.Close()
+			break
+		}
+		if err := recorder.Record(cmd, false); err != nil {
+			c.Log.Error(err)
+		}
+		c.CmdChan <- cmd
+	}
+}
+
+// Send sends the given command to the server, recording it if the session is being recorded. Commands are dropped while replaying.
+func (c *Client) Send(cmd network.Command) (err error) {
+	if c.Replaying {
+		return
+	}
+	if c.Recorder != nil {
+		if err := c.Recorder.Record(cmd, true); err != nil {
+			c.Log.Error(err)
+		}
+	}
+	return c.Connection.Send(cmd)
+}
+
+// RefreshImage requests the given image from the server if the DataManager does not have it and clears it from the UI, so that elements showing it look it up again. It is called for each of the DataManager's UpdatedImageIDs, which are sent from its decoders and so cannot use the connection themselves.
+func (c *Client) RefreshImage(id uint32) {
+	c.DataManager.EnsureImage(id)
+	c.UI.ImageClearChan <- ui.UpdateImageID(id)
+}
+
+// SetCacheNamespace switches the DataManager to the cache namespace of the given server, clearing images of the previous namespace from the UI and registering the cached sounds of the new one.
+func (c *Client) SetCacheNamespace(server string) {
+	if c.DataManager.Namespace() == data.CacheNamespace(server) {
+		return
+	}
+	cleared, err := c.DataManager.SetNamespace(server)
+	if err != nil {
+		c.Log.Error(err)
+	}
+	for _, id := range cleared {
+		c.UI.ImageClearChan <- ui.UpdateImageID(id)
+	}
+	// The audio loop is only running if audio was set up successfully.
+	if audio.GlobalInstance != nil {
+		for k, v := range c.DataManager.Sounds() {
+			c.Audio.CommandChannel <- audio.CommandNewSound{
+				ID:       k,
+				Type:     v.Type,
+				Filepath: v.Filepath,
+			}
+		}
+	}
+}
+
+// Fail reports an error that prevents a non-interactive login from continuing and stops the client with a non-zero exit code.
+func (c *Client) Fail(err error) {
+	c.Log.Error(err)
+	fmt.Fprintf(os.Stderr, "error: %s\n", err)
+	c.ExitCode = 1
+	c.UI.Running = false
+}
+
 // Print provides an interface to Log that is instantiated to the Client itself.
 func (c *Client) Print(format string, a ...interface{}) {
 	c.Log.Printf(format, a...)
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/chimera-rpg/go-server/network"
+)
+
+// SessionHeader is written at the start of a session file.
+type SessionHeader struct {
+	Server string // Server the session was recorded from, used to find its cached assets.
+}
+
+// SessionEntry is a single network command stored within a session file.
+type SessionEntry struct {
+	Time     time.Duration // Time elapsed since the session started.
+	Outgoing bool          // Whether the command was sent by the client rather than received from the server.
+	Command  network.Command
+}
+
+// Recorder writes network commands as SessionEntry values to a session file.
+type Recorder struct {
+	Filepath string
+	file     *os.File
+	encoder  *gob.Encoder
+	start    time.Time
+	lock     sync.Mutex
+}
+
+// NewRecorder creates the session file at the given path for the given server and returns a Recorder writing to it.
+func NewRecorder(filepath string, server string) (*Recorder, error) {
+	if err := os.MkdirAll(path.Dir(filepath), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.Create(filepath)
+	if err != nil {
+		return nil, err
+	}
+	encoder := gob.NewEncoder(file)
+	if err = encoder.Encode(SessionHeader{Server: server}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &Recorder{
+		Filepath: filepath,
+		file:     file,
+		encoder:  encoder,
+		start:    time.Now(),
+	}, nil
+}
+
+// Record writes the given command to the session file. Commands recorded after Close are ignored.
+func (r *Recorder) Record(cmd network.Command, outgoing bool) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	return r.encoder.Encode(SessionEntry{
+		Time:     time.Since(r.start),
+		Outgoing: outgoing,
+		Command:  cmd,
+	})
+}
+
+// Close closes the underlying session file.
+func (r *Recorder) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// ReadSession reads the header and all entries from the given session file.
+func ReadSession(filepath string) (header SessionHeader, entries []SessionEntry, err error) {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	decoder := gob.NewDecoder(file)
+	if err = decoder.Decode(&header); err != nil {
+		return
+	}
+	for {
+		var entry SessionEntry
+		if err = decoder.Decode(&entry); err != nil {
+			// A session that ended abruptly may have a truncated final entry, so we keep whatever was read.
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				err = nil
+			}
+			return
+		}
+		entries = append(entries, entry)
+	}
+}
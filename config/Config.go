@@ -13,6 +13,8 @@ type Config struct {
 	Window     WindowConfig
 	Game       GameConfig
 	Servers    map[string]*ServerConfig
+	Cache      CacheConfig
+	Audio      AudioConfig
 	LastServer string // Last server accessed by the client.
 	path       string `yaml:"-"`
 }
@@ -60,15 +62,84 @@ type GameConfig struct {
 
 // GameGraphicsConfig is the configuration for the game's graphics.
 type GameGraphicsConfig struct {
-	ObjectScale float64
+	ObjectScale  float64
+	ImageMemory  int // Megabytes of decoded images and of textures to keep in memory. The least recently used are unloaded beyond this.
+	MoveDuration int // Milliseconds objects take to slide to a new tile. Zero uses the default and a negative value disables sliding.
 }
 
 // ServerConfig is the configuration for per-server settings.
 type ServerConfig struct {
 	Username         string
-	Password         string
+	Password         string `yaml:",omitempty"` // Deprecated: passwords are stored in Credentials. Only read to migrate older configs.
 	Character        string
 	RememberPassword bool
+	Fingerprint      string // SHA-256 fingerprint of the server's TLS certificate, pinned on first connect.
+	RequireTLS       bool   // Forbids falling back to an insecure connection. Servers with a pinned Fingerprint never fall back.
+	Characters       map[string]*CharacterConfig
+}
+
+// CharacterConfig is the configuration for per-character settings.
+type CharacterConfig struct {
+	ObjectScale      float64 // Zoom of the map view. Zero uses the graphics' ObjectScale.
+	CameraX, CameraY float64 // Offset of the map view from the character in unscaled pixels, set by panning the camera.
+}
+
+// GetCharacterConfig returns the configuration for the given character, creating it if it does not exist.
+func (c *ServerConfig) GetCharacterConfig(name string) *CharacterConfig {
+	if c.Characters == nil {
+		c.Characters = make(map[string]*CharacterConfig)
+	}
+	if _, ok := c.Characters[name]; !ok {
+		c.Characters[name] = &CharacterConfig{}
+	}
+	return c.Characters[name]
+}
+
+// Server returns the configuration for the given server, creating it if it does not exist.
+func (c *Config) Server(name string) *ServerConfig {
+	if c.Servers == nil {
+		c.Servers = make(map[string]*ServerConfig)
+	}
+	if _, ok := c.Servers[name]; !ok {
+		c.Servers[name] = &ServerConfig{}
+	}
+	return c.Servers[name]
+}
+
+// CacheConfig is the configuration for the asset cache.
+type CacheConfig struct {
+	MaxSize int // Maximum size of all cached assets in megabytes. The least recently used assets are evicted beyond this.
+}
+
+// AudioConfig is the configuration of the audio mixer's buses and of music playback.
+type AudioConfig struct {
+	Master            BusConfig
+	Music             BusConfig
+	Effects           BusConfig
+	UI                BusConfig
+	Ambience          BusConfig
+	MusicFade         float64 // Seconds music takes to fade in, fade out, and crossfade.
+	MaxVoices         int     // Maximum number of sounds playing at once.
+	MaxVoicesPerSound int     // Maximum number of times a single sound plays at once.
+	SoundMemory       int     // Megabytes of decoded sounds to keep buffered. The least recently played are released beyond this.
+}
+
+// BusConfig is the configuration of a single audio bus.
+type BusConfig struct {
+	Volume float64 // 0-1
+	Muted  bool
+}
+
+// NewAudioConfig returns an AudioConfig with every bus at full volume and a two second music fade.
+func NewAudioConfig() AudioConfig {
+	return AudioConfig{
+		Master:    BusConfig{Volume: 1},
+		Music:     BusConfig{Volume: 1},
+		Effects:   BusConfig{Volume: 1},
+		UI:        BusConfig{Volume: 1},
+		Ambience:  BusConfig{Volume: 1},
+		MusicFade: 2,
+	}
 }
 
 // WindowConfig is the configuration of the window's sizes.
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// PassphraseEnv is the environment variable that holds the master passphrase. If it is not set, a locally generated key file is used instead. The key file is kept outside of the config directory, so a backup of the config alone cannot decrypt the credentials, but anyone able to read both files can. Set a passphrase to protect against that as well.
+const PassphraseEnv = "CHIMERA_PASSPHRASE"
+
+// ErrCredentialsLocked is returned when the credential file is protected by a master passphrase that was not provided.
+var ErrCredentialsLocked = errors.New("credentials are protected by a master passphrase, set " + PassphraseEnv + " to unlock them")
+
+// Credentials stores remembered passwords per server in an encrypted file.
+type Credentials struct {
+	Passwords map[string]string
+	path      string
+	keyPath   string
+	key       []byte
+	salt      []byte // Salt used to derive the key from the master passphrase. Empty when using the key file.
+}
+
+// credentialsFile is the on-disk representation of Credentials.
+type credentialsFile struct {
+	Salt  []byte
+	Nonce []byte
+	Data  []byte
+}
+
+// Open reads the credential file at p, unlocking it with the given passphrase or, if it is empty, with the key file at keyPath. Missing files are created on the next Write.
+func (c *Credentials) Open(p string, keyPath string, passphrase string) (err error) {
+	c.path = p
+	c.keyPath = keyPath
+	c.Passwords = make(map[string]string)
+	c.key = nil
+
+	var file credentialsFile
+	r, err := ioutil.ReadFile(p)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	} else if exists {
+		if err = json.Unmarshal(r, &file); err != nil {
+			return err
+		}
+	}
+
+	// The key is only kept once the file is known to decrypt with it, so that Write cannot replace credentials that failed to unlock.
+	var key []byte
+	if passphrase != "" {
+		c.salt = file.Salt
+		if len(c.salt) == 0 {
+			c.salt = make([]byte, 16)
+			if _, err = rand.Read(c.salt); err != nil {
+				return err
+			}
+		}
+		if key, err = scrypt.Key([]byte(passphrase), c.salt, 1<<15, 8, 1, 32); err != nil {
+			return err
+		}
+	} else {
+		if exists && len(file.Salt) > 0 {
+			return ErrCredentialsLocked
+		}
+		if key, err = c.readKeyFile(); err != nil {
+			return err
+		}
+	}
+
+	if !exists {
+		c.key = key
+		return nil
+	}
+
+	// Switching from the key file to a passphrase requires decrypting with the key file first.
+	openKey := key
+	if len(file.Salt) == 0 && passphrase != "" {
+		if openKey, err = c.readKeyFile(); err != nil {
+			return err
+		}
+	}
+
+	gcm, err := newGCM(openKey)
+	if err != nil {
+		return err
+	}
+	plain, err := gcm.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return fmt.Errorf("could not decrypt credentials: %w", err)
+	}
+	if err = json.Unmarshal(plain, &c.Passwords); err != nil {
+		return err
+	}
+	c.key = key
+	return nil
+}
+
+// Write encrypts and writes the credentials to disk.
+func (c *Credentials) Write() error {
+	if c.path == "" {
+		return fmt.Errorf("no credentials path defined")
+	}
+	if c.key == nil {
+		return ErrCredentialsLocked
+	}
+	plain, err := json.Marshal(c.Passwords)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(c.key)
+	if err != nil {
+		return err
+	}
+	file := credentialsFile{
+		Salt:  c.salt,
+		Nonce: make([]byte, gcm.NonceSize()),
+	}
+	if _, err = rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Data = gcm.Seal(nil, file.Nonce, plain, nil)
+
+	bytes, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, bytes, 0600)
+}
+
+// Get returns the remembered password for the given server.
+func (c *Credentials) Get(server string) string {
+	return c.Passwords[server]
+}
+
+// Set remembers the password for the given server. An empty password forgets it.
+func (c *Credentials) Set(server string, password string) {
+	if c.Passwords == nil {
+		c.Passwords = make(map[string]string)
+	}
+	if password == "" {
+		delete(c.Passwords, server)
+	} else {
+		c.Passwords[server] = password
+	}
+}
+
+// Migrate moves any plaintext passwords from the given config into the credentials, clearing them from the config. It returns true if anything was moved.
+func (c *Credentials) Migrate(conf *Config) (migrated bool) {
+	for name, sc := range conf.Servers {
+		if sc.Password == "" {
+			continue
+		}
+		c.Set(name, sc.Password)
+		sc.Password = ""
+		migrated = true
+	}
+	return
+}
+
+// readKeyFile reads the key file, generating it if it does not exist.
+func (c *Credentials) readKeyFile() ([]byte, error) {
+	key, err := ioutil.ReadFile(c.keyPath)
+	if err == nil {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("key file \"%s\" is invalid", c.keyPath)
+		}
+		return key, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	key = make([]byte, 32)
+	if _, err = rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err = ioutil.WriteFile(c.keyPath, key, 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
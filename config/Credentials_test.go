@@ -0,0 +1,121 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// openCredentials opens the credentials in dir with the given passphrase.
+func openCredentials(dir string, passphrase string) (*Credentials, error) {
+	c := &Credentials{}
+	err := c.Open(filepath.Join(dir, "credentials"), filepath.Join(dir, "credentials.key"), passphrase)
+	return c, err
+}
+
+// writeCredentials opens the credentials in dir with the given passphrase and writes the given password for "server".
+func writeCredentials(t *testing.T, dir string, passphrase string, password string) {
+	t.Helper()
+	c, err := openCredentials(dir, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Set("server", password)
+	if err := c.Write(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// checkCredentials opens the credentials in dir with the given passphrase and checks the password of "server".
+func checkCredentials(t *testing.T, dir string, passphrase string, password string) {
+	t.Helper()
+	c, err := openCredentials(dir, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Get("server"); got != password {
+		t.Errorf("got password %q, want %q", got, password)
+	}
+}
+
+func TestCredentialsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		passphrase string
+	}{
+		{"key file", ""},
+		{"passphrase", "hunter2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeCredentials(t, dir, tt.passphrase, "secret")
+			checkCredentials(t, dir, tt.passphrase, "secret")
+		})
+	}
+}
+
+func TestCredentialsWrongPassphrase(t *testing.T) {
+	dir := t.TempDir()
+	writeCredentials(t, dir, "hunter2", "secret")
+
+	c, err := openCredentials(dir, "hunter3")
+	if err == nil {
+		t.Fatal("opened credentials with the wrong passphrase")
+	}
+	// Credentials that failed to unlock must not be overwritten.
+	c.Set("server", "other")
+	if err := c.Write(); !errors.Is(err, ErrCredentialsLocked) {
+		t.Fatalf("got %v writing locked credentials, want %v", err, ErrCredentialsLocked)
+	}
+	checkCredentials(t, dir, "hunter2", "secret")
+
+	if _, err := openCredentials(dir, ""); !errors.Is(err, ErrCredentialsLocked) {
+		t.Fatalf("got %v opening without a passphrase, want %v", err, ErrCredentialsLocked)
+	}
+}
+
+func TestCredentialsSwitchToPassphrase(t *testing.T) {
+	dir := t.TempDir()
+	writeCredentials(t, dir, "", "secret")
+
+	// The first write with a passphrase re-encrypts the credentials of the key file.
+	checkCredentials(t, dir, "hunter2", "secret")
+	writeCredentials(t, dir, "hunter2", "secret")
+	checkCredentials(t, dir, "hunter2", "secret")
+
+	if _, err := openCredentials(dir, ""); !errors.Is(err, ErrCredentialsLocked) {
+		t.Fatalf("got %v opening with the key file, want %v", err, ErrCredentialsLocked)
+	}
+}
+
+func TestCredentialsMigrate(t *testing.T) {
+	dir := t.TempDir()
+	c, err := openCredentials(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := &Config{
+		Servers: map[string]*ServerConfig{
+			"a": {Username: "alice", Password: "secret"},
+			"b": {Username: "bob"},
+		},
+	}
+	if !c.Migrate(conf) {
+		t.Fatal("no passwords migrated")
+	}
+	for name, sc := range conf.Servers {
+		if sc.Password != "" {
+			t.Errorf("%s still has a plaintext password", name)
+		}
+	}
+	if got := c.Get("a"); got != "secret" {
+		t.Errorf("got password %q, want %q", got, "secret")
+	}
+	if got := c.Get("b"); got != "" {
+		t.Errorf("got password %q for a server without one", got)
+	}
+	if c.Migrate(conf) {
+		t.Error("migrated passwords twice")
+	}
+}
@@ -8,8 +8,42 @@ type Flags struct {
 	Character          string
 	Connect            string
 	Fullscreen         bool
+	FullscreenSet      bool // If the fullscreen flag was passed, in which case it overrides the window config either way.
 	GraphicsScale      float64
 	Profile            bool
+	Record             bool
+	Replay             string
+	CacheStats         bool
+	ClearCache         string // Server whose cache should be cleared, or ClearAllCaches.
+}
+
+// ClearAllCaches is the value of Flags.ClearCache if the caches of all servers should be cleared.
+const ClearAllCaches = "*"
+
+// optionalString is a string flag whose value may be omitted, in which case it is set to def.
+type optionalString struct {
+	value *string
+	def   string
+}
+
+func (o optionalString) String() string {
+	if o.value == nil {
+		return ""
+	}
+	return *o.value
+}
+
+func (o optionalString) Set(s string) error {
+	if s == "true" {
+		s = o.def
+	}
+	*o.value = s
+	return nil
+}
+
+// IsBoolFlag allows the flag to be passed without a value.
+func (o optionalString) IsBoolFlag() bool {
+	return true
 }
 
 // Parse calls flag.Parse() on its fields.
@@ -18,8 +52,22 @@ func (f *Flags) Parse() {
 	flag.StringVar(&f.Password, "password", "", "password")
 	flag.StringVar(&f.Character, "character", "", "name of character")
 	flag.StringVar(&f.Connect, "connect", "", "SERVER:PORT")
-	flag.Float64Var(&f.GraphicsScale, "scale", 4, "graphics scaling")
+	flag.Float64Var(&f.GraphicsScale, "scale", 0, "graphics scaling, overriding the configured scale")
 	flag.BoolVar(&f.Fullscreen, "fullscreen", false, "fullscreen")
 	flag.BoolVar(&f.Profile, "profile", false, "run pprof profiling on :6060")
+	flag.BoolVar(&f.Record, "record", false, "record network sessions to the cache directory")
+	flag.StringVar(&f.Replay, "replay", "", "replay a recorded session FILE")
+	flag.BoolVar(&f.CacheStats, "cache-stats", false, "print the size of each server's cache and exit")
+	flag.Var(optionalString{&f.ClearCache, ClearAllCaches}, "clear-cache", "clear the cache of SERVER, or of all servers if omitted, and exit")
 	flag.Parse()
+	flag.Visit(func(fl *flag.Flag) {
+		if fl.Name == "fullscreen" {
+			f.FullscreenSet = true
+		}
+	})
+}
+
+// NonInteractive returns if the flags provide everything needed to connect, log in, and select a character without any user interaction.
+func (f *Flags) NonInteractive() bool {
+	return f.Connect != "" && f.Username != "" && f.Password != "" && f.Character != ""
 }
@@ -0,0 +1,41 @@
+package data
+
+import (
+	"io/ioutil"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Environments that ambience beds are blended in for.
+const (
+	AmbienceOpen        = "open"        // Outdoors under open sky.
+	AmbienceSheltered   = "sheltered"   // Outdoors under a roof, such as inside a building.
+	AmbienceIndoor      = "indoor"      // Maps that are not outdoors but have ambient light, such as houses and dungeons.
+	AmbienceUnderground = "underground" // Maps that are not outdoors and have no ambient light, such as caves.
+)
+
+// AmbienceBed is a looping background sound that is blended in by how much the player is within its Environment. Beds are read from ambience.yaml in the data path, keyed by name:
+//
+//	wind:
+//	  File: ambience/wind.ogg
+//	  Environment: open
+//	  Volume: 0.6
+type AmbienceBed struct {
+	File        string  `yaml:"File"`        // Sound file relative to the data path.
+	Environment string  `yaml:"Environment"` // One of AmbienceOpen, AmbienceSheltered, AmbienceIndoor, or AmbienceUnderground.
+	Volume      float64 `yaml:"Volume"`      // Volume when fully within the environment.
+}
+
+// loadAmbience reads the ambience beds from the data path. A missing file disables ambience.
+func (m *Manager) loadAmbience() error {
+	m.Ambience = make(map[string]AmbienceBed)
+	r, err := ioutil.ReadFile(m.GetDataPath("ambience.yaml"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return yaml.Unmarshal(r, m.Ambience)
+}
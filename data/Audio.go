@@ -21,3 +21,6 @@ type SoundEntry struct {
 	Type     uint8  // See network sound command type
 	Pending  bool   // If the sound has been received yet.
 }
+
+// unknownSoundType is the Type of cached sounds whose network sound type is not known to the client, such as WAV or MP3 sounds. Their format is detected when they are decoded.
+const unknownSoundType = 255
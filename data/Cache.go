@@ -0,0 +1,261 @@
+package data
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultCacheSize is the cache size limit in megabytes used if none is configured.
+const DefaultCacheSize = 256
+
+// CacheStat provides the size and asset counts of a single cache namespace.
+type CacheStat struct {
+	Namespace string
+	Images    int
+	Sounds    int
+	Size      int64 // Total size in bytes.
+}
+
+func (s CacheStat) String() string {
+	return fmt.Sprintf("%s: %d images, %d sounds, %.2f MiB", s.Namespace, s.Images, s.Sounds, float64(s.Size)/(1024*1024))
+}
+
+// cacheDirs are the directories of each cache namespace that hold assets downloaded from the server. Other directories, such as the map memory under "maps", are kept by eviction and clearing.
+var cacheDirs = []string{"images", "sounds"}
+
+// cacheFile is a single cached asset considered for eviction.
+type cacheFile struct {
+	path     string
+	size     int64
+	accessed time.Time
+}
+
+// cacheLimit returns the configured cache size limit in bytes.
+func (m *Manager) cacheLimit() int64 {
+	limit := m.Config.Cache.MaxSize
+	if limit <= 0 {
+		limit = DefaultCacheSize
+	}
+	return int64(limit) * 1024 * 1024
+}
+
+// touchCacheFile marks the cached asset at the given path as accessed by updating its modification time, which is used to evict the least recently used assets first. Files are only touched once per run.
+func (m *Manager) touchCacheFile(file string) {
+	m.cacheLock.Lock()
+	defer m.cacheLock.Unlock()
+	if m.touchedFiles == nil {
+		m.touchedFiles = make(map[string]struct{})
+	}
+	if _, ok := m.touchedFiles[file]; ok {
+		return
+	}
+	m.touchedFiles[file] = struct{}{}
+	now := time.Now()
+	if err := os.Chtimes(file, now, now); err != nil && !os.IsNotExist(err) {
+		m.Log.Warn("[Manager] ", err)
+	}
+}
+
+// addCacheSize adds the given number of bytes to the cache size, evicting assets if the limit is exceeded.
+func (m *Manager) addCacheSize(size int64) {
+	m.cacheLock.Lock()
+	m.cacheSize += size
+	exceeded := m.cacheSize > m.cacheLimit()
+	m.cacheLock.Unlock()
+	if exceeded {
+		if err := m.EvictCache(); err != nil {
+			m.Log.Warn("[Manager] ", err)
+		}
+	}
+}
+
+// cacheNamespaces returns the names of all cache namespaces.
+func (m *Manager) cacheNamespaces() (namespaces []string, err error) {
+	entries, err := os.ReadDir(m.GetCachePath("servers"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			namespaces = append(namespaces, entry.Name())
+		}
+	}
+	return
+}
+
+// collectCacheFiles returns all cached assets of every namespace.
+func (m *Manager) collectCacheFiles() (files []cacheFile, err error) {
+	namespaces, err := m.cacheNamespaces()
+	if err != nil {
+		return
+	}
+	for _, namespace := range namespaces {
+		for _, dir := range cacheDirs {
+			err = filepath.Walk(m.GetCachePath("servers", namespace, dir), func(p string, info os.FileInfo, err error) error {
+				if err != nil {
+					if os.IsNotExist(err) {
+						return nil
+					}
+					return err
+				}
+				if !info.IsDir() {
+					files = append(files, cacheFile{
+						path:     p,
+						size:     info.Size(),
+						accessed: info.ModTime(),
+					})
+				}
+				return nil
+			})
+			if err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// EvictCache removes the least recently used assets until the cache fits within its size limit. Assets used during this run are kept.
+func (m *Manager) EvictCache() error {
+	files, err := m.collectCacheFiles()
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+
+	m.cacheLock.Lock()
+	limit := m.cacheLimit()
+	var evicted []string
+	if total > limit {
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].accessed.Before(files[j].accessed)
+		})
+		for _, f := range files {
+			if total <= limit {
+				break
+			}
+			if _, ok := m.touchedFiles[f.path]; ok {
+				continue
+			}
+			if err := os.Remove(f.path); err != nil {
+				m.Log.Warn("[Manager] ", err)
+				continue
+			}
+			total -= f.size
+			evicted = append(evicted, f.path)
+		}
+		m.Log.Printf("[Manager] Evicted %d cached assets", len(evicted))
+	}
+	m.cacheSize = total
+	m.cacheLock.Unlock()
+
+	// This is done without cacheLock held, as imageLock is otherwise taken before it.
+	m.forgetCacheFiles(evicted)
+	return nil
+}
+
+// forgetCacheFiles forgets the sounds and images loaded from the given evicted files so that they are requested again on their next use. Decoded images are kept, but can no longer be unloaded.
+func (m *Manager) forgetCacheFiles(paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	evicted := make(map[string]struct{}, len(paths))
+	for _, p := range paths {
+		evicted[p] = struct{}{}
+	}
+
+	for id, entry := range m.sounds {
+		if _, ok := evicted[entry.Filepath]; !ok {
+			continue
+		}
+		delete(m.sounds, id)
+		if m.evictSound != nil {
+			m.evictSound(id)
+		}
+	}
+
+	m.imageLock.Lock()
+	defer m.imageLock.Unlock()
+	images := m.images[:0]
+	for _, ref := range m.images {
+		if _, ok := evicted[ref.path]; ok {
+			if !ref.Ready {
+				if m.evictedImages == nil {
+					m.evictedImages = make(map[uint32]struct{})
+				}
+				m.evictedImages[ref.ID] = struct{}{}
+				continue
+			}
+			ref.path = ""
+		}
+		images = append(images, ref)
+	}
+	m.images = images
+}
+
+// CacheStats returns the size and asset counts of each cache namespace.
+func (m *Manager) CacheStats() (stats []CacheStat, err error) {
+	files, err := m.collectCacheFiles()
+	if err != nil {
+		return
+	}
+	serversPath := m.GetCachePath("servers")
+	namespaces := make(map[string]*CacheStat)
+	for _, f := range files {
+		rel, err := filepath.Rel(serversPath, f.path)
+		if err != nil {
+			continue
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if len(parts) < 3 {
+			continue
+		}
+		stat, ok := namespaces[parts[0]]
+		if !ok {
+			stat = &CacheStat{Namespace: parts[0]}
+			namespaces[parts[0]] = stat
+		}
+		stat.Size += f.size
+		if parts[1] == "images" {
+			stat.Images++
+		} else if parts[1] == "sounds" {
+			stat.Sounds++
+		}
+	}
+	for _, stat := range namespaces {
+		stats = append(stats, *stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Namespace < stats[j].Namespace
+	})
+	return
+}
+
+// ClearCache removes the cached assets of the given server. If server is empty, the cached assets of all servers are removed. Map memory is kept.
+func (m *Manager) ClearCache(server string) error {
+	namespaces := []string{CacheNamespace(server)}
+	if server == "" {
+		var err error
+		if namespaces, err = m.cacheNamespaces(); err != nil {
+			return err
+		}
+	}
+	for _, namespace := range namespaces {
+		for _, dir := range cacheDirs {
+			if err := os.RemoveAll(m.GetCachePath("servers", namespace, dir)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package data
+
+import (
+	"image"
+	"runtime"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultImageMemory is the memory budget in megabytes for decoded images used if none is configured.
+const DefaultImageMemory = 256
+
+const (
+	maxImageDecoders = 4                // Maximum number of background image decoders.
+	imageIdleTime    = 30 * time.Second // Time an image must go unused before it may be unloaded.
+)
+
+// imageDecodeRequest is a cached image queued for decoding.
+type imageDecodeRequest struct {
+	id   uint32
+	path string
+}
+
+// ImageMemory returns the configured memory budget for decoded images in bytes.
+func (m *Manager) ImageMemory() int64 {
+	budget := m.Config.Game.Graphics.ImageMemory
+	if budget <= 0 {
+		budget = DefaultImageMemory
+	}
+	return int64(budget) * 1024 * 1024
+}
+
+// imagePath returns the path of the given image within the active cache namespace.
+func (m *Manager) imagePath(iID uint32) string {
+	return m.GetNamespacePath("images", strconv.FormatUint(uint64(iID), 10)+".png")
+}
+
+// imageSize returns the approximate memory used by a decoded image.
+func imageSize(img image.Image) int64 {
+	if img == nil {
+		return 0
+	}
+	return int64(img.Bounds().Dx()) * int64(img.Bounds().Dy()) * 4
+}
+
+// startImageDecoders starts the background workers that decode cached images.
+func (m *Manager) startImageDecoders() {
+	m.decodeQueue = make(chan imageDecodeRequest, 1000)
+	workers := runtime.NumCPU()
+	if workers > maxImageDecoders {
+		workers = maxImageDecoders
+	}
+	for i := 0; i < workers; i++ {
+		go m.decodeImages()
+	}
+}
+
+// queueImageDecode queues the given image for decoding. If the queue is full, the image is queued again on its next access. Must be called with imageLock held.
+func (m *Manager) queueImageDecode(ref *ImageRef) {
+	select {
+	case m.decodeQueue <- imageDecodeRequest{id: ref.ID, path: ref.path}:
+		ref.decoding = true
+	default:
+	}
+}
+
+// decodeImages decodes queued images and notifies UpdatedImageIDs once each is ready, or once a broken one is forgotten.
+func (m *Manager) decodeImages() {
+	for req := range m.decodeQueue {
+		img, err := m.GetImage(req.path)
+
+		m.imageLock.Lock()
+		index := -1
+		for i, ref := range m.images {
+			// The path also differs if the namespace was switched in the meantime.
+			if ref.ID == req.id && ref.path == req.path {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			m.imageLock.Unlock()
+			continue
+		}
+		if err != nil {
+			// Forget the broken image so that it is requested from the server again.
+			m.images = append(m.images[:index], m.images[index+1:]...)
+			m.imageLock.Unlock()
+			m.Log.WithFields(logrus.Fields{
+				"ID": req.id,
+			}).Warn("[Manager] Could not decode cached image: ", err)
+			// The main loop requests the image from the server again, as EnsureImage finds it missing.
+			m.UpdatedImageIDs <- req.id
+			continue
+		}
+		ref := &m.images[index]
+		ref.img = img
+		ref.Ready = true
+		ref.decoding = false
+		ref.size = imageSize(img)
+		ref.lastUsed = time.Now()
+		m.imageMemory += ref.size
+		unloaded := m.unloadImages()
+		m.imageLock.Unlock()
+
+		if m.unloadImage != nil {
+			for _, id := range unloaded {
+				m.unloadImage(id)
+			}
+		}
+		m.UpdatedImageIDs <- req.id
+	}
+}
+
+// unloadImages unloads the least recently used decoded images until they fit within the memory budget and returns their IDs. Only images that can be decoded again from the cache and that have not been used recently are unloaded. Must be called with imageLock held.
+func (m *Manager) unloadImages() (unloaded []uint32) {
+	budget := m.ImageMemory()
+	if m.imageMemory <= budget {
+		return
+	}
+	var candidates []int
+	for i, ref := range m.images {
+		if ref.Ready && ref.path != "" && time.Since(ref.lastUsed) > imageIdleTime {
+			candidates = append(candidates, i)
+		}
+	}
+	sort.Slice(candidates, func(a, b int) bool {
+		return m.images[candidates[a]].lastUsed.Before(m.images[candidates[b]].lastUsed)
+	})
+	for _, i := range candidates {
+		if m.imageMemory <= budget {
+			break
+		}
+		ref := &m.images[i]
+		m.imageMemory -= ref.size
+		ref.img = nil
+		ref.Ready = false
+		ref.size = 0
+		unloaded = append(unloaded, ref.ID)
+	}
+	return
+}
+
+// setImagePath sets the path the given image can be decoded from again once it has been unloaded.
+func (m *Manager) setImagePath(iID uint32, path string) {
+	m.imageLock.Lock()
+	defer m.imageLock.Unlock()
+	for i, ref := range m.images {
+		if ref.ID == iID {
+			m.images[i].path = path
+			return
+		}
+	}
+}
@@ -14,6 +14,13 @@ func (m *Manager) acquireConfigPath() (err error) {
 	return
 }
 
+func (m *Manager) acquireKeyPath() (err error) {
+	dir := path.Join(os.Getenv("HOME"), "Library/Application Support")
+
+	m.KeyPath = path.Join(dir, "chimera-keys")
+	return
+}
+
 func (m *Manager) acquireCachePath() (err error) {
 	dir := path.Join(os.Getenv("HOME"), "Library/Caches")
 
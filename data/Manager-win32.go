@@ -14,6 +14,14 @@ func (m *Manager) acquireConfigPath() (err error) {
 	return
 }
 
+func (m *Manager) acquireKeyPath() (err error) {
+	// Unlike APPDATA, LOCALAPPDATA does not roam with the user's profile.
+	dir := path.Join(os.Getenv("LOCALAPPDATA"), "chimera", "keys")
+
+	m.KeyPath = dir
+	return
+}
+
 func (m *Manager) acquireCachePath() (err error) {
 	dir := path.Join(os.Getenv("LOCALAPPDATA"), "chimera")
 
@@ -17,6 +17,16 @@ func (m *Manager) acquireConfigPath() (err error) {
 	return
 }
 
+func (m *Manager) acquireKeyPath() (err error) {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		dir = path.Join(os.Getenv("HOME"), ".local/share")
+	}
+
+	m.KeyPath = path.Join(dir, "chimera")
+	return
+}
+
 func (m *Manager) acquireCachePath() (err error) {
 	dir := os.Getenv("XDG_CACHE_HOME")
 	if dir == "" {
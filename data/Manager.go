@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	// Package image/png is not used explicitly in the code below,
 	// but is imported for its initialization side-effect, which allows
@@ -27,23 +28,35 @@ import (
 )
 
 type ImageRef struct {
-	ID    uint32
-	Ready bool
-	img   image.Image
+	ID       uint32
+	Ready    bool
+	img      image.Image
+	path     string    // Path to the cached image, if it can be decoded from disk.
+	decoding bool      // Whether the image is queued for decoding.
+	size     int64     // Approximate memory used by the decoded image.
+	lastUsed time.Time // Last time the image was retrieved.
+}
+
+// Sender is the interface used by the Manager to send network requests.
+type Sender interface {
+	Send(cmd network.Command) error
 }
 
 // Manager handles access to files on the system.
 type Manager struct {
-	Conn       *network.Connection
-	Log        *logrus.Logger
-	DataPath   string // Path for client data (fonts, etc.)
-	ConfigPath string // Path for user configuration (style overrides, bindings, etc.)
-	Config     config.Config
-	CachePath  string                       // Path for local cache (downloaded PNGs, etc.)
-	Styles     map[string]map[string]string // Map of UI styles.
-	Layouts    map[string][]*ui.LayoutEntry
-	animations []*Animation
-	audio      map[uint32]Audio
+	Conn        Sender
+	Log         *logrus.Logger
+	DataPath    string // Path for client data (fonts, etc.)
+	ConfigPath  string // Path for user configuration (style overrides, bindings, etc.)
+	Config      config.Config
+	Credentials config.Credentials           // Remembered passwords, stored encrypted under ConfigPath.
+	KeyPath     string                       // Path for the credentials key file, kept apart from ConfigPath so that config backups do not carry it.
+	CachePath   string                       // Path for local cache (downloaded PNGs, etc.)
+	Styles      map[string]map[string]string // Map of UI styles.
+	Layouts     map[string][]*ui.LayoutEntry
+	Ambience    map[string]AmbienceBed // Ambience beds by name.
+	animations  []*Animation
+	audio       map[uint32]Audio
 	//images         map[uint32]image.Image
 	images          []ImageRef
 	imageLock       sync.Mutex
@@ -51,6 +64,15 @@ type Manager struct {
 	LoadingImage    image.Image
 	sounds          map[uint32]SoundEntry
 	handleCallback  func(netID int, cmd network.Command)
+	evictSound      func(soundID uint32) // Called for each registered sound whose cached file was evicted.
+	unloadImage     func(imageID uint32) // Called for each decoded image unloaded to fit the memory budget.
+	namespace       string               // Cache namespace of the active server. See SetNamespace.
+	cacheLock       sync.Mutex
+	cacheSize       int64               // Total size of all cached assets in bytes.
+	touchedFiles    map[string]struct{} // Cached assets accessed during this run.
+	decodeQueue     chan imageDecodeRequest
+	evictedImages   map[uint32]struct{} // Images forgotten after their cached files were evicted, requested again on their next use.
+	imageMemory     int64               // Approximate memory used by decoded images.
 }
 
 // Setup gets the required data/config/cache paths and creates them if needed.
@@ -64,6 +86,9 @@ func (m *Manager) Setup(l *logrus.Logger) (err error) {
 	if err = m.acquireConfigPath(); err != nil {
 		return
 	}
+	if err = m.acquireKeyPath(); err != nil {
+		return
+	}
 	if err = m.acquireCachePath(); err != nil {
 		return
 	}
@@ -80,39 +105,47 @@ func (m *Manager) Setup(l *logrus.Logger) (err error) {
 			return
 		}
 	}
-	if _, err = os.Stat(m.CachePath); err != nil {
-		if os.IsNotExist(err) {
-			err = os.MkdirAll(m.CachePath, 0755)
-		}
-		if err != nil {
-			return
-		}
-	}
-	// Also ensure images directory exists.
-	imagesPath := path.Join(m.CachePath, "images")
-	if _, err = os.Stat(imagesPath); err != nil {
+	if _, err = os.Stat(m.KeyPath); err != nil {
 		if os.IsNotExist(err) {
-			err = os.MkdirAll(imagesPath, 0755)
+			err = os.MkdirAll(m.KeyPath, 0700)
 		}
 		if err != nil {
 			return
 		}
 	}
-	// Also ensure sounds directory exists.
-	soundsPath := path.Join(m.CachePath, "sounds")
-	if _, err = os.Stat(soundsPath); err != nil {
+	if _, err = os.Stat(m.CachePath); err != nil {
 		if os.IsNotExist(err) {
-			err = os.MkdirAll(soundsPath, 0755)
+			err = os.MkdirAll(m.CachePath, 0755)
 		}
 		if err != nil {
 			return
 		}
 	}
-
 	// Read in our config.
+	m.Config.Audio = config.NewAudioConfig()
 	if err := m.Config.Read(path.Join(m.ConfigPath, "client.yaml")); err != nil {
 		m.Log.Info(err)
 	}
+	// Unlock our remembered passwords, moving over any that older versions stored in plaintext.
+	if err := m.moveCredentialsKey(); err != nil {
+		m.Log.Warn(err)
+	}
+	if err := m.Credentials.Open(path.Join(m.ConfigPath, "credentials"), m.GetKeyPath("credentials.key"), os.Getenv(config.PassphraseEnv)); err != nil {
+		m.Log.Warn(err)
+		for name, sc := range m.Config.Servers {
+			if sc.Password != "" {
+				m.Log.Warnf("The remembered password for %s is still stored in plaintext, as it can only be moved to the credentials file once that is unlocked.", name)
+			}
+		}
+	} else if m.Credentials.Migrate(&m.Config) {
+		if err := m.Credentials.Write(); err != nil {
+			m.Log.Warn(err)
+		} else if err := m.Config.Write(); err != nil {
+			m.Log.Warn(err)
+		} else {
+			m.Log.Info("Migrated remembered passwords to the encrypted credentials file.")
+		}
+	}
 	// TODO: Make a func to ensure validity of config structure.
 	m.Config.Game.Containers = make(map[string]*config.ContainerConfig)
 
@@ -146,6 +179,11 @@ func (m *Manager) Setup(l *logrus.Logger) (err error) {
 		}
 	}
 
+	// Read in our ambience beds.
+	if err := m.loadAmbience(); err != nil {
+		m.Log.Warn("[Manager] Could not read ambience: ", err)
+	}
+
 	m.animations = make([]*Animation, 0)
 	m.audio = make(map[uint32]Audio)
 	//m.images = make(map[uint32]image.Image)
@@ -158,24 +196,141 @@ func (m *Manager) Setup(l *logrus.Logger) (err error) {
 	}
 	m.LoadingImage = imageData
 
+	// Cached images are decoded in the background as they are needed.
+	m.startImageDecoders()
+
+	// Move over caches from before they were namespaced per server.
+	if err := m.migrateCache(); err != nil {
+		m.Log.Error("[Manager] ", err)
+	}
+
+	// Load the cache of the server we're most likely to connect to.
+	if _, err := m.SetNamespace(m.Config.LastServer); err != nil {
+		m.Log.Error("[Manager] ", err)
+	}
+	// Also bring the cache within its size limit.
+	if err := m.EvictCache(); err != nil {
+		m.Log.Error("[Manager] ", err)
+	}
+	return
+}
+
+// CacheNamespace returns the name of the cache namespace used for the given server.
+func CacheNamespace(server string) string {
+	if server == "" {
+		return "default"
+	}
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, server)
+}
+
+// Namespace returns the active cache namespace.
+func (m *Manager) Namespace() string {
+	return m.namespace
+}
+
+// SetNamespace switches to the cache namespace of the given server, replacing all loaded images, sounds, and animations with those of the server. The IDs of the images that were loaded beforehand are returned so they can be cleared from the UI.
+func (m *Manager) SetNamespace(server string) (cleared []uint32, err error) {
+	namespace := CacheNamespace(server)
+	if namespace == m.namespace {
+		return
+	}
+
+	m.imageLock.Lock()
+	for _, ref := range m.images {
+		cleared = append(cleared, ref.ID)
+	}
+	m.images = nil
+	m.imageMemory = 0
+	m.evictedImages = nil
+	m.imageLock.Unlock()
+	m.sounds = make(map[uint32]SoundEntry)
+	m.audio = make(map[uint32]Audio)
+	m.animations = make([]*Animation, 0)
+	m.namespace = namespace
+
+	// Ensure our namespace's directories exist.
+	for _, dir := range []string{"images", "sounds"} {
+		if err = os.MkdirAll(m.GetNamespacePath(dir), 0755); err != nil {
+			return
+		}
+	}
+
 	// Collect cached images.
 	if err = m.collectCachedImages(); err != nil {
-		m.Log.Error("[Manager] ", err)
+		return
 	}
 	m.Log.WithFields(logrus.Fields{
-		"Count": len(m.images),
-	}).Print("Loaded cached images")
+		"Namespace": namespace,
+		"Count":     len(m.images),
+	}).Print("Found cached images")
 
 	// Collect cached sounds.
 	if err = m.collectCachedSounds(); err != nil {
-		m.Log.Error("[Manager] ", err)
+		return
 	}
 	m.Log.WithFields(logrus.Fields{
-		"Count": len(m.sounds),
+		"Namespace": namespace,
+		"Count":     len(m.sounds),
 	}).Print("Loaded cached sounds")
 	return
 }
 
+// migrateCache moves the images and sounds cached before caches were namespaced into the namespace of the last accessed server, as that is where they most likely came from. Without a last server they go to the default namespace. Files are merged into any existing cache, leaving those that would replace a file in place.
+func (m *Manager) migrateCache() error {
+	for _, dir := range []string{"images", "sounds"} {
+		legacyPath := m.GetCachePath(dir)
+		if _, err := os.Stat(legacyPath); os.IsNotExist(err) {
+			continue
+		}
+		targetPath := m.GetCachePath("servers", CacheNamespace(m.Config.LastServer), dir)
+		var dirs []string
+		moved, kept := 0, 0
+		err := filepath.Walk(legacyPath, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				dirs = append(dirs, p)
+				return nil
+			}
+			target := filepath.Join(targetPath, p[len(legacyPath):])
+			if _, err := os.Stat(target); err == nil {
+				kept++
+				return nil
+			} else if !os.IsNotExist(err) {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := os.Rename(p, target); err != nil {
+				return err
+			}
+			moved++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		// Remove the directories left empty, deepest first.
+		for i := len(dirs) - 1; i >= 0; i-- {
+			if entries, err := ioutil.ReadDir(dirs[i]); err == nil && len(entries) == 0 {
+				os.Remove(dirs[i])
+			}
+		}
+		m.Log.Printf("[Manager] Migrated %d cached %s to %s", moved, dir, targetPath)
+		if kept > 0 {
+			m.Log.Printf("[Manager] Left %d cached %s in %s that are already in %s", kept, dir, legacyPath, targetPath)
+		}
+	}
+	return nil
+}
+
 // GetDataPath gets a path relative to the data path directory.
 func (m *Manager) GetDataPath(parts ...string) string {
 	return path.Join(m.DataPath, path.Clean("/"+path.Join(parts...)))
@@ -186,6 +341,35 @@ func (m *Manager) GetCachePath(parts ...string) string {
 	return path.Join(m.CachePath, path.Clean("/"+path.Join(parts...)))
 }
 
+// GetNamespacePath gets a path relative to the active cache namespace directory.
+func (m *Manager) GetNamespacePath(parts ...string) string {
+	return path.Join(m.GetCachePath("servers", m.namespace), path.Clean("/"+path.Join(parts...)))
+}
+
+// GetKeyPath gets a path relative to the key path directory.
+func (m *Manager) GetKeyPath(parts ...string) string {
+	return path.Join(m.KeyPath, path.Clean("/"+path.Join(parts...)))
+}
+
+// moveCredentialsKey moves a credentials key file that older versions kept beside the config over to the key path.
+func (m *Manager) moveCredentialsKey() error {
+	legacy := m.GetConfigPath("credentials.key")
+	key, err := ioutil.ReadFile(legacy)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if _, err = os.Stat(m.GetKeyPath("credentials.key")); os.IsNotExist(err) {
+		if err = ioutil.WriteFile(m.GetKeyPath("credentials.key"), key, 0600); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	return os.Remove(legacy)
+}
+
 // GetConfigPath gets a path relative to the config path directory.
 func (m *Manager) GetConfigPath(parts ...string) string {
 	return path.Join(m.ConfigPath, path.Clean("/"+path.Join(parts...)))
@@ -213,9 +397,9 @@ func (m *Manager) Images() []ImageRef {
 	return m.images
 }
 
-// collectCachedImages reads the cache directory for images to load.
+// collectCachedImages reads the cache directory for images that can be loaded. Images are only decoded once they are first needed.
 func (m *Manager) collectCachedImages() (err error) {
-	imagesPath := path.Join(m.CachePath, "images")
+	imagesPath := m.GetNamespacePath("images")
 	err = filepath.Walk(imagesPath, func(filepath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -229,13 +413,10 @@ func (m *Manager) collectCachedImages() (err error) {
 					m.Log.Warn("[Manager] ", err)
 					return nil
 				}
-				i := uint32(ui64)
-				img, err := m.GetImage(filepath)
-				if err != nil {
-					m.Log.Warn("[Manager] ", err)
-					return nil
-				}
-				m.SetCachedImage(i, img, true, true)
+				m.images = append(m.images, ImageRef{
+					ID:   uint32(ui64),
+					path: filepath,
+				})
 			}
 		}
 		return nil
@@ -245,7 +426,7 @@ func (m *Manager) collectCachedImages() (err error) {
 
 // collectCachedSounds reads the cache directory for sounds to load.
 func (m *Manager) collectCachedSounds() (err error) {
-	soundsPath := path.Join(m.CachePath, "sounds")
+	soundsPath := m.GetNamespacePath("sounds")
 	err = filepath.Walk(soundsPath, func(filepath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -262,6 +443,10 @@ func (m *Manager) collectCachedSounds() (err error) {
 				shortpath = shortpath[:len(shortpath)-len(".ogg")]
 				dataType = network.SoundOgg
 				knownType = true
+			} else if strings.HasSuffix(filepath, ".sound") {
+				shortpath = shortpath[:len(shortpath)-len(".sound")]
+				dataType = unknownSoundType
+				knownType = true
 			}
 			if knownType {
 				ui64, err := strconv.ParseUint(shortpath, 10, 32)
@@ -281,24 +466,39 @@ func (m *Manager) collectCachedSounds() (err error) {
 	return
 }
 
-// WriteImage writes image data to the images subdirectory in the cachePath.
+// WriteImage writes image data to the images subdirectory of the active cache namespace.
 func (m *Manager) WriteImage(imageID uint32, imageType uint8, data []byte) error {
-	targetPath := path.Join(m.CachePath, "images", strconv.FormatUint(uint64(imageID), 10))
+	targetPath := m.GetNamespacePath("images", strconv.FormatUint(uint64(imageID), 10))
 	if imageType == network.GraphicsPng {
-		targetPath = targetPath + ".png"
+		targetPath = m.imagePath(imageID)
 	}
-	return m.WriteBytes(targetPath, data)
+	return m.writeCacheFile(targetPath, data)
 }
 
-// WriteSound writes sound data to the sounds subdirectory in the cachePath.
+// WriteSound writes sound data to the sounds subdirectory of the active cache namespace.
 func (m *Manager) WriteSound(soundID uint32, soundType uint8, data []byte) error {
-	targetPath := path.Join(m.CachePath, "sounds", strconv.FormatUint(uint64(soundID), 10))
+	return m.writeCacheFile(m.soundPath(soundID, soundType), data)
+}
+
+// soundPath returns the path of the given sound within the active cache namespace. Sounds of types the client does not know, such as WAV or MP3 sounds, use a generic extension.
+func (m *Manager) soundPath(soundID uint32, soundType uint8) string {
+	targetPath := m.GetNamespacePath("sounds", strconv.FormatUint(uint64(soundID), 10))
 	if soundType == network.SoundFlac {
-		targetPath = targetPath + ".flac"
+		return targetPath + ".flac"
 	} else if soundType == network.SoundOgg {
-		targetPath = targetPath + ".ogg"
+		return targetPath + ".ogg"
 	}
-	return m.WriteBytes(targetPath, data)
+	return targetPath + ".sound"
+}
+
+// writeCacheFile writes a cached asset, marking it as accessed and counting it towards the cache size limit.
+func (m *Manager) writeCacheFile(file string, data []byte) error {
+	if err := m.WriteBytes(file, data); err != nil {
+		return err
+	}
+	m.touchCacheFile(file)
+	m.addCacheSize(int64(len(data)))
+	return nil
 }
 
 // WriteBytes writes bytes to a file path.
@@ -349,6 +549,7 @@ func (m *Manager) GetImage(file string) (img image.Image, err error) {
 	if err != nil {
 		return
 	}
+	defer reader.Close()
 	img, _, err = image.Decode(reader)
 	return
 
@@ -382,10 +583,31 @@ func (m *Manager) GetAnimation(aID uint32) *Animation {
 func (m *Manager) GetCachedImage(iID uint32) (img image.Image, err error) {
 	m.imageLock.Lock()
 	defer m.imageLock.Unlock()
-	for _, ref := range m.images {
-		if ref.ID == iID && ref.Ready {
+	if _, ok := m.evictedImages[iID]; ok {
+		// The main loop requests the image from the server again, as EnsureImage finds it missing. If UpdatedImageIDs is full, this is retried on the next access.
+		select {
+		case m.UpdatedImageIDs <- iID:
+			delete(m.evictedImages, iID)
+		default:
+		}
+	}
+	for i := range m.images {
+		ref := &m.images[i]
+		if ref.ID != iID {
+			continue
+		}
+		if ref.Ready {
+			ref.lastUsed = time.Now()
+			if ref.path != "" {
+				m.touchCacheFile(ref.path)
+			}
 			return ref.img, nil
 		}
+		// Decode the image from the cache, notifying UpdatedImageIDs once it is ready.
+		if ref.path != "" && !ref.decoding {
+			m.queueImageDecode(ref)
+		}
+		break
 	}
 	if m.LoadingImage == nil {
 		return nil, errors.New("missing")
@@ -399,18 +621,25 @@ func (m *Manager) SetCachedImage(iID uint32, img image.Image, override bool, rea
 	for i, ref := range m.images {
 		if ref.ID == iID {
 			if override {
+				m.imageMemory -= ref.size
 				ref.img = img
 				ref.Ready = ready
+				ref.size = imageSize(img)
+				ref.lastUsed = time.Now()
+				m.imageMemory += ref.size
 			}
 			m.images[i] = ref
 			return
 		}
 	}
 	m.images = append(m.images, ImageRef{
-		ID:    iID,
-		img:   img,
-		Ready: ready,
+		ID:       iID,
+		img:      img,
+		Ready:    ready,
+		size:     imageSize(img),
+		lastUsed: time.Now(),
 	})
+	m.imageMemory += imageSize(img)
 }
 
 // GetAudioSound returns the associated Sound for an audioID, soundID, and index.
@@ -426,6 +655,13 @@ func (m *Manager) GetAudioSound(audioID, soundID uint32, index int) (Sound, bool
 	if len(sound) == 0 {
 		return Sound{}, false
 	}
+	// The sound is requested again if its cached file was evicted.
+	if !m.EnsureSound(sound[0].SoundID) {
+		return Sound{}, false
+	}
+	if entry := m.sounds[sound[0].SoundID]; entry.Filepath != "" {
+		m.touchCacheFile(entry.Filepath)
+	}
 	return sound[0], true
 }
 
@@ -515,12 +751,14 @@ func (m *Manager) HandleAnimationCommand(cmd network.CommandAnimation) error {
 // EnsureImage ensures that the given image is available. If it is not, then send a graphics request.
 func (m *Manager) EnsureImage(iID uint32) {
 	exists := false
+	m.imageLock.Lock()
 	for _, ref := range m.images {
 		if iID == ref.ID {
 			exists = true
 			break
 		}
 	}
+	m.imageLock.Unlock()
 	if !exists {
 		imageData, err := m.GetImage(m.GetDataPath("ui/loading.png"))
 		if err != nil {
@@ -572,6 +810,8 @@ func (m *Manager) HandleGraphicsCommand(cmd network.CommandGraphics) error {
 			// Also write the image to disk for future use.
 			if err := m.WriteImage(cmd.GraphicsID, cmd.DataType, cmd.Data); err != nil {
 				m.Log.Warn("[Manager] ", err)
+			} else {
+				m.setImagePath(cmd.GraphicsID, m.imagePath(cmd.GraphicsID))
 			}
 		} else {
 			m.Log.Warn("[Manager] Unhandled Graphics Type")
@@ -673,25 +913,18 @@ func (m *Manager) HandleSoundCommand(cmd network.CommandSound) error {
 			Pending: false,
 		}
 	} else if cmd.Type == network.Set {
-		if cmd.DataType == network.SoundFlac || cmd.DataType == network.SoundOgg {
-			// Write the sound to disk for future use.
-			if err := m.WriteSound(cmd.SoundID, cmd.DataType, cmd.Data); err != nil {
-				m.Log.Warn("[Manager] ", err)
-			}
-
-			// Acquire file path and get our reader. This is a rough duplicate of our initial caching code.
-			targetPath := path.Join(m.CachePath, "sounds", strconv.FormatUint(uint64(cmd.SoundID), 10))
-			if cmd.DataType == network.SoundFlac {
-				targetPath = targetPath + ".flac"
-			} else if cmd.DataType == network.SoundOgg {
-				targetPath = targetPath + ".ogg"
-			}
-			m.sounds[cmd.SoundID] = SoundEntry{
-				Filepath: targetPath,
-				Type:     cmd.DataType,
-			}
-		} else {
-			m.Log.Warn("[Manager] Unhandled Sound Type")
+		// Sounds of types we do not know, such as WAV or MP3 sounds, are still stored, as their format is detected when decoded.
+		dataType := cmd.DataType
+		if dataType != network.SoundFlac && dataType != network.SoundOgg {
+			dataType = unknownSoundType
+		}
+		// Write the sound to disk for future use.
+		if err := m.WriteSound(cmd.SoundID, dataType, cmd.Data); err != nil {
+			m.Log.Warn("[Manager] ", err)
+		}
+		m.sounds[cmd.SoundID] = SoundEntry{
+			Filepath: m.soundPath(cmd.SoundID, dataType),
+			Type:     dataType,
 		}
 	} else {
 		m.Log.Warn("[Manager] Bogus Sound Message")
@@ -706,3 +939,13 @@ func (m *Manager) HandleSoundCommand(cmd network.CommandSound) error {
 func (m *Manager) SetHandleCallback(handleCallback func(netID int, cmd network.Command)) {
 	m.handleCallback = handleCallback
 }
+
+// SetUnloadImageCallback sets the func called with the ID of each decoded image that is unloaded.
+func (m *Manager) SetUnloadImageCallback(unloadImage func(imageID uint32)) {
+	m.unloadImage = unloadImage
+}
+
+// SetEvictSoundCallback sets the func called with the ID of each sound whose cached file is evicted.
+func (m *Manager) SetEvictSoundCallback(evictSound func(soundID uint32)) {
+	m.evictSound = evictSound
+}
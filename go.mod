@@ -8,6 +8,7 @@ require (
 	github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646
 	github.com/sirupsen/logrus v1.9.0
 	github.com/veandco/go-sdl2 v0.4.12
+	golang.org/x/crypto v0.1.0
 	golang.org/x/exp v0.0.0-20220823124025-807a23277127
 	golang.org/x/mobile v0.0.0-20220112015953-858099ff7816
 	gopkg.in/yaml.v2 v2.4.0
@@ -15,6 +16,7 @@ require (
 
 require (
 	github.com/cosmos72/gomacro v0.0.0-20221020183653-9aafa23692e7 // indirect
+	github.com/hajimehoshi/go-mp3 v0.3.0 // indirect
 	github.com/hajimehoshi/oto v1.0.1 // indirect
 	github.com/icza/bitio v1.0.0 // indirect
 	github.com/imdario/mergo v0.3.13 // indirect
@@ -30,7 +32,6 @@ require (
 	github.com/peterh/liner v1.2.2 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/rivo/uniseg v0.4.2 // indirect
-	golang.org/x/crypto v0.1.0 // indirect
 	golang.org/x/exp/shiny v0.0.0-20230522175609-2e198f4a06a1 // indirect
 	golang.org/x/image v0.0.0-20190802002840-cff245a6509b // indirect
 	golang.org/x/mod v0.6.0 // indirect
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"fmt"
+	"os"
 	"runtime"
 	"runtime/debug"
 
@@ -11,8 +13,10 @@ import (
 
 	"github.com/chimera-rpg/go-client/audio"
 	"github.com/chimera-rpg/go-client/client"
+	"github.com/chimera-rpg/go-client/config"
 	"github.com/chimera-rpg/go-client/data"
 	"github.com/chimera-rpg/go-client/states/list"
+	"github.com/chimera-rpg/go-client/states/replay"
 	"github.com/chimera-rpg/go-client/ui"
 )
 
@@ -25,7 +29,14 @@ func main() {
 	var clientInstance client.Client
 	var uiInstance ui.Instance
 	var audioInstance audio.Instance
+	var exitCode int
 
+	// Deferred first so that it runs after all other cleanup.
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
 	defer func() {
 		if r := recover(); r != nil {
 			ui.ShowError("%v", r.(error).Error())
@@ -39,7 +50,38 @@ func main() {
 		ui.ShowError("%s", err)
 	}
 
-	// Setup our UI
+	// Parse our flags before the UI, as they can override the window config.
+	clientInstance.Flags.Parse()
+
+	// Handle cache management flags without starting the UI.
+	if clientInstance.Flags.CacheStats || clientInstance.Flags.ClearCache != "" {
+		if err = manageCache(&dataManager, clientInstance.Flags); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err)
+			exitCode = 1
+		}
+		return
+	}
+
+	// Setup our UI with the saved window geometry, writing back any changes to it.
+	windowConfig := &dataManager.Config.Window
+	uiInstance.WindowGeometry = ui.WindowGeometry{
+		X:          int32(windowConfig.X),
+		Y:          int32(windowConfig.Y),
+		W:          int32(windowConfig.Width),
+		H:          int32(windowConfig.Height),
+		Fullscreen: windowConfig.Fullscreen,
+	}
+	if clientInstance.Flags.FullscreenSet {
+		uiInstance.WindowGeometry.Fullscreen = clientInstance.Flags.Fullscreen
+	}
+	uiInstance.OnWindowChanged = func(g ui.WindowGeometry) {
+		windowConfig.X = int(g.X)
+		windowConfig.Y = int(g.Y)
+		windowConfig.Width = int(g.W)
+		windowConfig.Height = int(g.H)
+		windowConfig.Fullscreen = g.Fullscreen
+	}
+	uiInstance.TextureMemory = dataManager.ImageMemory()
 	if err = uiInstance.Setup(&dataManager); err != nil {
 		ui.ShowError("%s", err)
 		return
@@ -47,15 +89,20 @@ func main() {
 	defer uiInstance.Cleanup()
 
 	ui.GlobalInstance = &uiInstance
+	// Let the UI drop its references to unloaded images so that their memory is actually freed.
+	dataManager.SetUnloadImageCallback(func(imageID uint32) {
+		uiInstance.ImageUnloadChan <- ui.UpdateImageID(imageID)
+	})
 
 	// Setup our Audio
+	audioInstance.SoundMemory = int64(dataManager.Config.Audio.SoundMemory) * 1024 * 1024
 	if err = audioInstance.Setup(log); err != nil {
 		ui.ShowError("%s", err)
 	} else {
 		go audioInstance.Loop()
 		defer audioInstance.Quit()
 		audio.GlobalInstance = &audioInstance
-		// FIXME: This isn't the right place for this.
+		// Register our cached sounds. They are only decoded once played.
 		for k, v := range dataManager.Sounds() {
 			audioInstance.CommandChannel <- audio.CommandNewSound{
 				ID:       k,
@@ -63,11 +110,20 @@ func main() {
 				Filepath: v.Filepath,
 			}
 		}
-	}
-
-	// Preload our graphics. FIXME: This isn't the right place for this either.
-	for _, v := range dataManager.Images() {
-		uiInstance.ImageLoadChan <- ui.UpdateImageID(v.ID)
+		// Forget sounds whose cached files are evicted, so that they are requested again when next played.
+		dataManager.SetEvictSoundCallback(func(soundID uint32) {
+			audioInstance.CommandChannel <- audio.CommandRemoveSound{ID: soundID}
+		})
+		// Apply the configured mixer volumes.
+		for _, bus := range audio.Buses {
+			conf := audio.BusConfig(&dataManager.Config.Audio, bus)
+			audioInstance.CommandChannel <- audio.CommandSetVolume{Bus: bus, Volume: conf.Volume}
+			audioInstance.CommandChannel <- audio.CommandSetMute{Bus: bus, Muted: conf.Muted}
+		}
+		audioInstance.CommandChannel <- audio.CommandSetVoiceLimits{
+			Total:    dataManager.Config.Audio.MaxVoices,
+			PerSound: dataManager.Config.Audio.MaxVoicesPerSound,
+		}
 	}
 
 	// Setup our Client
@@ -87,15 +143,45 @@ func main() {
 		}()
 	}
 
-	// Automatically attempt to connect if the server flag was passed
-	if len(clientInstance.Flags.Connect) > 0 {
-		clientInstance.StateChannel <- client.StateMessage{State: &list.Handshake{}, Args: clientInstance.Flags.Connect}
+	// Replay a recorded session if requested, otherwise automatically attempt to connect if the server flag was passed
+	if len(clientInstance.Flags.Replay) > 0 {
+		clientInstance.StateChannel <- client.StateMessage{State: &replay.Replay{}, Args: clientInstance.Flags.Replay}
+	} else if len(clientInstance.Flags.Connect) > 0 {
+		// The server list stays at the bottom of the stack so that failed logins and reconnects return to it.
+		clientInstance.StateChannel <- client.StateMessage{State: &list.List{}, Args: nil}
+		clientInstance.StateChannel <- client.StateMessage{Push: true, State: &list.Handshake{}, Args: clientInstance.Flags.Connect}
 	} else {
 		clientInstance.StateChannel <- client.StateMessage{State: &list.List{}, Args: nil}
 	}
 
 	// Start our UI Loop.
 	uiInstance.Loop()
+	exitCode = clientInstance.ExitCode
 
 	log.Print("Sayonara!")
 }
+
+// manageCache clears and prints the stats of the asset cache as requested by the flags.
+func manageCache(dataManager *data.Manager, flags config.Flags) error {
+	if flags.ClearCache == config.ClearAllCaches {
+		if err := dataManager.ClearCache(""); err != nil {
+			return err
+		}
+		fmt.Println("Cleared the caches of all servers.")
+	} else if flags.ClearCache != "" {
+		if err := dataManager.ClearCache(flags.ClearCache); err != nil {
+			return err
+		}
+		fmt.Printf("Cleared the cache of %s.\n", flags.ClearCache)
+	}
+	if flags.CacheStats {
+		stats, err := dataManager.CacheStats()
+		if err != nil {
+			return err
+		}
+		for _, stat := range stats {
+			fmt.Println(stat)
+		}
+	}
+	return nil
+}
@@ -15,7 +15,6 @@ import (
 	cdata "github.com/chimera-rpg/go-server/data"
 	"github.com/chimera-rpg/go-server/network"
 	"github.com/sirupsen/logrus"
-	"github.com/veandco/go-sdl2/sdl"
 )
 
 type CommandMode = int
@@ -50,6 +49,7 @@ type Game struct {
 	MapWindow            elements.MapWindow
 	GroundWindow         elements.ContainerWindow
 	DebugWindow          elements.DebugWindow
+	MinimapWindow        elements.MinimapWindow
 	StatsWindow          ui.Container
 	StateWindow          ui.Container
 	statusElements       map[cdata.StatusType]ui.ElementI
@@ -66,28 +66,67 @@ type Game struct {
 	objectsScale         *float64               // Pointer to config graphics.
 	pendingNoiseCommands []network.CommandNoise // Pending noises, for sounds that have not loaded yet.
 	pendingMusicCommands []network.CommandMusic // Pending music, for sounds that have not loaded yet.
+	noises               []noise                // Recent positional sounds.
+	lastVoiceID          uint32
+	listener             [3]int // Last position sent to the audio listener.
+	hasListener          bool
+	ambience             map[string]float64 // Volumes last sent for each ambience bed.
+	memorySaved          time.Time          // Last time the map memory was saved.
+	path                 *pathWalk          // Path being walked by clicking on the map.
+	slidingObjects       map[*world.Object]struct{}
+	cameraX, cameraY     float64 // Map scroll centering the view object, before following its slide.
+	cameraChanged        bool
+	characterConfig      *config.CharacterConfig // Zoom and camera offset of the character being played.
+	zoomFrom, zoomTarget float64
+	zoomStart            time.Time
+	relayout             bool  // If every object is being laid out again this frame.
+	dragX, dragY         int32 // Last mouse position while dragging the camera.
 	focusedObjectID      uint32
 	hoveredObjectID      uint32
 	focusedImage         ui.ElementI
 	eventHooks           map[interface{}][]func(e interface{})
 }
 
+// Resume carries local state that survives a reconnect. Container window modes are kept in the client config and so survive on their own.
+type Resume struct {
+	MessageHistory []Message
+	CommandMode    CommandMode
+}
+
 // Init our Game state.
 func (s *Game) Init(t interface{}) (state client.StateI, nextArgs interface{}, err error) {
+	resume, _ := t.(*Resume)
 	s.inputChan = make(chan interface{})
 	s.objectShadows = make(map[uint32]ui.ElementI)
 	s.statuses = make(map[cdata.StatusType]bool)
 	s.statusElements = make(map[cdata.StatusType]ui.ElementI)
 	s.repeatingKeys = make(map[uint8]int)
 	s.heldButtons = make(map[uint8]bool)
+	s.ambience = make(map[string]float64)
+	s.slidingObjects = make(map[*world.Object]struct{})
 	s.SetupBinds()
 	s.CommandMode = CommandModeChat
+	if resume != nil {
+		s.MessageHistory = resume.MessageHistory
+		s.CommandMode = resume.CommandMode
+	}
 	// Initialize our world.
 	s.world.Init(s.Client.DataManager, s.Client.Log)
+	s.SetupMapMemory()
 	s.eventHooks = make(map[interface{}][]func(e interface{}))
 
 	// This is lazy(tm), but we're just resending all pendingNoiseCommands on receipt of a sound or audio network command.
 	s.Client.DataManager.SetHandleCallback(func(netID int, cmd network.Command) {
+		// Register newly received sounds with the audio instance.
+		if c, ok := cmd.(network.CommandSound); ok {
+			if entry := s.Client.DataManager.GetCachedSound(c.SoundID); entry.Filepath != "" {
+				s.sendAudio(audio.CommandNewSound{
+					ID:       c.SoundID,
+					Type:     entry.Type,
+					Filepath: entry.Filepath,
+				})
+			}
+		}
 		if netID == network.TypeSound || netID == network.TypeAudio {
 			if len(s.pendingNoiseCommands) > 0 {
 				pending := s.pendingNoiseCommands
@@ -113,17 +152,26 @@ func (s *Game) Init(t interface{}) (state client.StateI, nextArgs interface{}, e
 
 	s.SetupUI()
 
+	if resume != nil {
+		s.Print("Reconnected to server.")
+	}
+
 	go s.Loop()
 	return
 }
 
 // Close our Game state.
 func (s *Game) Close() {
-	go func() {
-		s.Client.Connection.Close()
-	}()
+	// Close blocks until its ClosedChan is read, so a copy of the connection is closed in the background. This leaves alone any connection made in the meantime, such as by a reconnect.
+	if s.Client.Connection.IsConnected {
+		conn := s.Client.Connection
+		s.Client.Connection.IsConnected = false
+		go conn.Close()
+	}
 	s.CleanupUI()
-	s.Client.Audio.CommandChannel <- audio.CommandStopAllMusic{}
+	s.sendAudio(audio.CommandStopAllMusic{FadeOut: s.musicFade()})
+	s.StopAmbience()
+	s.SaveMapMemory(true)
 }
 
 // Loop is our loop for managing network activity and beyond.
@@ -140,12 +188,20 @@ func (s *Game) Loop() {
 				return
 			}
 		case id := <-s.Client.DataManager.UpdatedImageIDs:
-			s.Client.UI.ImageClearChan <- ui.UpdateImageID(id)
+			s.Client.RefreshImage(id)
 			s.world.CheckPendingObjectImageIDs(id)
 		case <-s.Client.ClosedChan:
 			s.Client.Log.Print("Lost connection to server.")
 			ticker.Stop()
-			s.Client.StateChannel <- client.StateMessage{PopToTop: true, Args: nil}
+			if s.canReconnect() {
+				// The server list picks up the Resume and begins the reconnect.
+				s.Client.StateChannel <- client.StateMessage{PopToTop: true, Args: &Resume{
+					MessageHistory: s.MessageHistory,
+					CommandMode:    s.CommandMode,
+				}}
+			} else {
+				s.Client.StateChannel <- client.StateMessage{PopToTop: true, Args: nil}
+			}
 			return
 		case inp := <-s.inputChan:
 			switch e := inp.(type) {
@@ -158,7 +214,7 @@ func (s *Game) Loop() {
 				// Remove
 				s.bindings.Trigger(binds.KeyGroup{
 					Keys:      []uint8{e.code},
-					Modifiers: e.modifiers &^ sdl.KMOD_NUM, // Remove numlock as a modifier
+					Modifiers: e.modifiers &^ ui.KeyModNum, // Remove numlock as a modifier
 					Pressed:   e.pressed,
 					Repeat:    e.repeat,
 					OnRepeat:  s.repeatingKeys[e.code],
@@ -193,12 +249,22 @@ func (s *Game) Loop() {
 				}
 			case elements.MouseInput:
 				if e.Button == 3 {
+					s.CancelPath()
 					s.MoveWithMouse(e)
+				} else if e.Button == 2 {
+					s.DragCamera(e)
 				}
+			case elements.MouseWheelInput:
+				s.Zoom(int(e.Y))
+			case elements.MoveToEvent:
+				s.MoveTo(e.Y, e.X, e.Z)
 			case elements.MouseMoveInput:
 				if s.heldButtons[3] {
 					s.RunWithMouse(e.X, e.Y)
 				}
+				if s.heldButtons[2] {
+					s.DragCameraTo(e.X, e.Y)
+				}
 			case elements.FocusObjectEvent:
 				s.FocusObject(e.ID)
 				for _, cb := range s.eventHooks[elements.FocusObjectEvent{}] {
@@ -227,10 +293,38 @@ func (s *Game) Loop() {
 		}
 		s.HandleRender(delta)
 		s.UpdateGroundWindow()
+		s.UpdatePath()
+		s.UpdateListener()
+		s.UpdateAmbience()
+		s.DebugWindow.RefreshVoices()
+		s.MinimapWindow.Refresh()
+		s.SaveMapMemory(false)
 		lastTs = ts
 	}
 }
 
+// canReconnect returns if the current server has stored credentials that allow logging back in without user interaction.
+func (s *Game) canReconnect() bool {
+	if s.Client.Replaying {
+		return false
+	}
+	sc, ok := s.Client.DataManager.Config.Servers[s.Client.CurrentServer]
+	return ok && sc.Username != "" && s.Client.DataManager.Credentials.Get(s.Client.CurrentServer) != "" && sc.Character != ""
+}
+
+// musicFade returns the configured time music takes to fade.
+func (s *Game) musicFade() time.Duration {
+	return time.Duration(s.Client.DataManager.Config.Audio.MusicFade * float64(time.Second))
+}
+
+// sendAudio sends the given command to the audio instance. Commands are dropped if audio could not be set up, as its loop is then not running to receive them.
+func (s *Game) sendAudio(cmd audio.CommandI) {
+	if audio.GlobalInstance == nil {
+		return
+	}
+	s.Client.Audio.CommandChannel <- cmd
+}
+
 // HandleNet handles the network code for our Game state.
 func (s *Game) HandleNet(cmd network.Command) bool {
 	switch c := cmd.(type) {
@@ -264,6 +358,9 @@ func (s *Game) HandleNet(cmd network.Command) bool {
 			}
 			s.Client.RootWindow.BatchChannel <- batchMessages.messages
 		}
+		for o := range s.slidingObjects {
+			s.stopSlide(o)
+		}
 		s.world.HandleMapCommand(c)
 	case network.CommandObject:
 		switch p := c.Payload.(type) {
@@ -314,26 +411,38 @@ func (s *Game) HandleNet(cmd network.Command) bool {
 		if !ok {
 			s.pendingNoiseCommands = append(s.pendingNoiseCommands, c)
 		} else {
-			s.Client.Audio.CommandChannel <- audio.CommandPlaySound{
-				ID:     snd.SoundID,
-				Volume: c.Volume,
-			}
+			s.PlayNoise(snd.SoundID, c.Volume, int(c.Y), int(c.X), int(c.Z))
 			if m, err := s.createMapMessage(int(c.Y), int(c.X), int(c.Z), "*"+snd.Text+"*", color.RGBA{128, 200, 255, 220}); err == nil {
 				s.MapWindow.Messages = append(s.MapWindow.Messages, m)
 				s.MapWindow.Container.GetAdoptChannel() <- m.El
 			}
 		}
 	case network.CommandMusic:
+		if c.Stop {
+			s.sendAudio(audio.CommandStopMusic{
+				PlaybackID: c.ObjectID,
+				FadeOut:    s.musicFade(),
+			})
+			break
+		}
 		s.Client.DataManager.EnsureAudio(c.AudioID)
 		snd, ok := s.Client.DataManager.GetAudioSound(c.AudioID, c.SoundID, 0)
 		if !ok {
 			s.pendingMusicCommands = append(s.pendingMusicCommands, c)
 		} else {
-			s.Client.Audio.CommandChannel <- audio.CommandPlayMusic{
+			play := audio.CommandPlayMusic{
 				ID:         snd.SoundID,
 				PlaybackID: c.ObjectID,
 				Volume:     c.Volume,
-				//Loop:       c.Loop,
+				Loop:       int(c.Loop),
+				FadeIn:     s.musicFade(),
+				Crossfade:  true,
+			}
+			// Music that ends on its own waits for the current music, while music that plays forever replaces it.
+			if play.Loop >= 0 {
+				s.sendAudio(audio.CommandQueueMusic{CommandPlayMusic: play})
+			} else {
+				s.sendAudio(play)
 			}
 			// TODO: Some sort of "you hear music..." then add some credits?
 			/*if m, err := s.createMapMessage(c.Y, c.X, c.Z, "*"+snd.Text+"*", color.RGBA{128, 200, 255, 220}); err == nil {
@@ -398,8 +507,14 @@ func (s *Game) HandleMessageCommand(m network.CommandMessage) {
 	s.UpdateMessagesWindow()
 }
 
+// mouseAngle returns the angle of the mouse from the view object, which is off the map's center while the camera is panned.
+func (s *Game) mouseAngle(x, y int32) float64 {
+	ox, oy := s.cameraOffset()
+	return s.MapWindow.MouseAngleFromView(x+int32(ox), y+int32(oy))
+}
+
 func (s *Game) RunWithMouse(x, y int32) {
-	dA := s.MapWindow.MouseAngleFromView(x, y)
+	dA := s.mouseAngle(x, y)
 	if dA >= 315 || dA <= 45 {
 		if s.runDirection != network.East {
 			s.bindings.RunFunction("east run")
@@ -420,7 +535,7 @@ func (s *Game) RunWithMouse(x, y int32) {
 }
 
 func (s *Game) MoveWithMouse(e elements.MouseInput) {
-	dA := s.MapWindow.MouseAngleFromView(e.X, e.Y)
+	dA := s.mouseAngle(e.X, e.Y)
 	/****
 	    	275
 	  225 		315
@@ -471,6 +586,11 @@ func (s *Game) World() *world.World {
 	return &s.world
 }
 
+// Voices returns the sounds that are currently playing.
+func (s *Game) Voices() []audio.VoiceInfo {
+	return s.Client.Audio.Voices()
+}
+
 func (s *Game) FocusObject(e uint32) {
 	if s.focusedObjectID == s.hoveredObjectID && e != s.hoveredObjectID {
 		s.focusedObjectID = e
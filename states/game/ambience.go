@@ -0,0 +1,70 @@
+package game
+
+import (
+	"math"
+	"time"
+
+	"github.com/chimera-rpg/go-client/audio"
+	"github.com/chimera-rpg/go-client/data"
+)
+
+const (
+	ambienceRadius    = 2               // Tiles around the listener sampled for sky exposure.
+	ambienceFade      = 3 * time.Second // Time ambience beds take to blend to a new volume.
+	ambienceThreshold = 0.05            // Smallest volume change sent to the audio instance.
+	ambienceDark      = 32              // Maps that are not outdoors count as underground if no channel of their ambient light reaches this.
+)
+
+// ambienceWeights returns how much the listener is within each ambience environment.
+func (s *Game) ambienceWeights() map[string]float64 {
+	m := s.world.GetCurrentMap()
+	if m == nil || !s.hasListener {
+		return nil
+	}
+	if !m.Outdoor() {
+		if r, g, b := m.AmbientRGB(); r < ambienceDark && g < ambienceDark && b < ambienceDark {
+			return map[string]float64{data.AmbienceUnderground: 1}
+		}
+		return map[string]float64{data.AmbienceIndoor: 1}
+	}
+	sky := s.world.SkyExposure(s.listener[0], s.listener[1], s.listener[2], ambienceRadius)
+	return map[string]float64{
+		data.AmbienceOpen:      sky,
+		data.AmbienceSheltered: 1 - sky,
+	}
+}
+
+// UpdateAmbience blends the ambience beds towards the listener's surroundings.
+func (s *Game) UpdateAmbience() {
+	weights := s.ambienceWeights()
+	for name, bed := range s.Client.DataManager.Ambience {
+		volume := bed.Volume * weights[bed.Environment]
+		current := s.ambience[name]
+		// Small changes are skipped, but beds are always silenced fully.
+		if math.Abs(volume-current) < ambienceThreshold && (volume > 0 || current == 0) {
+			continue
+		}
+		s.setAmbience(name, volume)
+	}
+}
+
+// StopAmbience fades out every playing ambience bed.
+func (s *Game) StopAmbience() {
+	for name := range s.ambience {
+		s.setAmbience(name, 0)
+	}
+}
+
+func (s *Game) setAmbience(name string, volume float64) {
+	if volume > 0 {
+		s.ambience[name] = volume
+	} else {
+		delete(s.ambience, name)
+	}
+	s.sendAudio(audio.CommandSetAmbience{
+		Name:     name,
+		Filepath: s.Client.DataManager.GetDataPath(s.Client.DataManager.Ambience[name].File),
+		Volume:   volume,
+		Fade:     ambienceFade,
+	})
+}
@@ -354,6 +354,72 @@ var (
 		Keys:    []uint8{47},
 		Pressed: true,
 	}
+	defaultToggleFullscreen = binds.KeyGroup{
+		Keys:      []uint8{13}, // alt+enter
+		Modifiers: 256,
+		Pressed:   true,
+	}
+	defaultMinimap = binds.KeyGroup{
+		Keys:    []uint8{109}, // m
+		Pressed: true,
+	}
+	defaultMinimapZoomIn = binds.KeyGroup{
+		Keys:      []uint8{61}, // shift+=
+		Modifiers: 1,
+		Pressed:   true,
+	}
+	defaultMinimapZoomOut = binds.KeyGroup{
+		Keys:      []uint8{45}, // shift+-
+		Modifiers: 1,
+		Pressed:   true,
+	}
+	defaultZoomIn = binds.KeyGroup{
+		Keys:    []uint8{61}, // =
+		Pressed: true,
+	}
+	defaultZoomOut = binds.KeyGroup{
+		Keys:    []uint8{45}, // -
+		Pressed: true,
+	}
+	defaultCameraNorth = binds.KeyGroup{
+		Keys:      []uint8{82}, // ctrl+up
+		Modifiers: 64,
+		Pressed:   true,
+	}
+	defaultCameraSouth = binds.KeyGroup{
+		Keys:      []uint8{81}, // ctrl+down
+		Modifiers: 64,
+		Pressed:   true,
+	}
+	defaultCameraWest = binds.KeyGroup{
+		Keys:      []uint8{80}, // ctrl+left
+		Modifiers: 64,
+		Pressed:   true,
+	}
+	defaultCameraEast = binds.KeyGroup{
+		Keys:      []uint8{79}, // ctrl+right
+		Modifiers: 64,
+		Pressed:   true,
+	}
+	defaultCenterCamera = binds.KeyGroup{
+		Keys:    []uint8{74}, // home
+		Pressed: true,
+	}
+	defaultMute = binds.KeyGroup{
+		Keys:      []uint8{109}, // ctrl+m
+		Modifiers: 64,
+		Pressed:   true,
+	}
+	defaultVolumeUp = binds.KeyGroup{
+		Keys:      []uint8{61}, // ctrl+=
+		Modifiers: 64,
+		Pressed:   true,
+	}
+	defaultVolumeDown = binds.KeyGroup{
+		Keys:      []uint8{45}, // ctrl+-
+		Modifiers: 64,
+		Pressed:   true,
+	}
 )
 
 func (s *Game) SetupBinds() {
@@ -369,14 +435,16 @@ func (s *Game) SetupBinds() {
 	s.bindings.SetFunction("debug", func(i ...interface{}) {
 		s.DebugWindow.Toggle()
 	})
-	// Movement
+	// Movement. Moving by hand stops walking to a clicked tile.
 	s.bindings.SetFunction("north", func(i ...interface{}) {
+		s.CancelPath()
 		s.runDirection = network.North
 		s.Client.Send(network.CommandCmd{
 			Cmd: network.North,
 		})
 	})
 	s.bindings.SetFunction("north run", func(i ...interface{}) {
+		s.CancelPath()
 		s.runDirection = network.North
 		s.Client.Send(network.CommandRepeatCmd{
 			Cmd: network.North,
@@ -389,12 +457,14 @@ func (s *Game) SetupBinds() {
 		})
 	})
 	s.bindings.SetFunction("south", func(i ...interface{}) {
+		s.CancelPath()
 		s.runDirection = network.South
 		s.Client.Send(network.CommandCmd{
 			Cmd: network.South,
 		})
 	})
 	s.bindings.SetFunction("south run", func(i ...interface{}) {
+		s.CancelPath()
 		s.runDirection = network.South
 		s.Client.Send(network.CommandRepeatCmd{
 			Cmd: network.South,
@@ -407,12 +477,14 @@ func (s *Game) SetupBinds() {
 		})
 	})
 	s.bindings.SetFunction("east", func(i ...interface{}) {
+		s.CancelPath()
 		s.runDirection = network.East
 		s.Client.Send(network.CommandCmd{
 			Cmd: network.East,
 		})
 	})
 	s.bindings.SetFunction("east run", func(i ...interface{}) {
+		s.CancelPath()
 		s.runDirection = network.East
 		s.Client.Send(network.CommandRepeatCmd{
 			Cmd: network.East,
@@ -425,12 +497,14 @@ func (s *Game) SetupBinds() {
 		})
 	})
 	s.bindings.SetFunction("west", func(i ...interface{}) {
+		s.CancelPath()
 		s.runDirection = network.West
 		s.Client.Send(network.CommandCmd{
 			Cmd: network.West,
 		})
 	})
 	s.bindings.SetFunction("west run", func(i ...interface{}) {
+		s.CancelPath()
 		s.runDirection = network.West
 		s.Client.Send(network.CommandRepeatCmd{
 			Cmd: network.West,
@@ -443,12 +517,14 @@ func (s *Game) SetupBinds() {
 		})
 	})
 	s.bindings.SetFunction("up", func(i ...interface{}) {
+		s.CancelPath()
 		s.runDirection = network.Up
 		s.Client.Send(network.CommandCmd{
 			Cmd: network.Up,
 		})
 	})
 	s.bindings.SetFunction("up run", func(i ...interface{}) {
+		s.CancelPath()
 		s.runDirection = network.Up
 		s.Client.Send(network.CommandRepeatCmd{
 			Cmd: network.Up,
@@ -461,12 +537,14 @@ func (s *Game) SetupBinds() {
 		})
 	})
 	s.bindings.SetFunction("down", func(i ...interface{}) {
+		s.CancelPath()
 		s.runDirection = network.Down
 		s.Client.Send(network.CommandCmd{
 			Cmd: network.Down,
 		})
 	})
 	s.bindings.SetFunction("down run", func(i ...interface{}) {
+		s.CancelPath()
 		s.runDirection = network.Down
 		s.Client.Send(network.CommandRepeatCmd{
 			Cmd: network.Down,
@@ -736,6 +814,55 @@ func (s *Game) SetupBinds() {
 		s.ChatInput.GetUpdateChannel() <- ui.UpdateValue{Value: "/"}
 	})
 
+	s.bindings.SetFunction("toggle fullscreen", func(i ...interface{}) {
+		select {
+		case s.Client.UI.FullscreenChan <- struct{}{}:
+		default:
+		}
+	})
+	// Minimap
+	s.bindings.SetFunction("minimap", func(i ...interface{}) {
+		s.MinimapWindow.Toggle()
+	})
+	s.bindings.SetFunction("minimap zoom in", func(i ...interface{}) {
+		s.MinimapWindow.Zoom(1)
+	})
+	s.bindings.SetFunction("minimap zoom out", func(i ...interface{}) {
+		s.MinimapWindow.Zoom(-1)
+	})
+	// Camera
+	s.bindings.SetFunction("zoom in", func(i ...interface{}) {
+		s.Zoom(1)
+	})
+	s.bindings.SetFunction("zoom out", func(i ...interface{}) {
+		s.Zoom(-1)
+	})
+	s.bindings.SetFunction("camera north", func(i ...interface{}) {
+		s.PanCamera(0, -float64(s.Client.AnimationsConfig.TileHeight))
+	})
+	s.bindings.SetFunction("camera south", func(i ...interface{}) {
+		s.PanCamera(0, float64(s.Client.AnimationsConfig.TileHeight))
+	})
+	s.bindings.SetFunction("camera west", func(i ...interface{}) {
+		s.PanCamera(-float64(s.Client.AnimationsConfig.TileWidth), 0)
+	})
+	s.bindings.SetFunction("camera east", func(i ...interface{}) {
+		s.PanCamera(float64(s.Client.AnimationsConfig.TileWidth), 0)
+	})
+	s.bindings.SetFunction("center camera", func(i ...interface{}) {
+		s.CenterCamera()
+	})
+	// Audio
+	s.bindings.SetFunction("mute", func(i ...interface{}) {
+		s.ToggleMute(busFromArgs(i))
+	})
+	s.bindings.SetFunction("volume up", func(i ...interface{}) {
+		s.ChangeVolume(busFromArgs(i), volumeStep)
+	})
+	s.bindings.SetFunction("volume down", func(i ...interface{}) {
+		s.ChangeVolume(busFromArgs(i), -volumeStep)
+	})
+
 	if len(s.bindings.Keygroups) == 0 {
 		if !s.bindings.HasKeygroupsForName("clear commands") {
 			s.bindings.AddKeygroup("clear commands", defaultClearCommands)
@@ -872,5 +999,47 @@ func (s *Game) SetupBinds() {
 		if !s.bindings.HasKeygroupsForName("focus cmd") {
 			s.bindings.AddKeygroup("focus cmd", defaultFocusCommand)
 		}
+		if !s.bindings.HasKeygroupsForName("toggle fullscreen") {
+			s.bindings.AddKeygroup("toggle fullscreen", defaultToggleFullscreen)
+		}
+		if !s.bindings.HasKeygroupsForName("minimap") {
+			s.bindings.AddKeygroup("minimap", defaultMinimap)
+		}
+		if !s.bindings.HasKeygroupsForName("minimap zoom in") {
+			s.bindings.AddKeygroup("minimap zoom in", defaultMinimapZoomIn)
+		}
+		if !s.bindings.HasKeygroupsForName("minimap zoom out") {
+			s.bindings.AddKeygroup("minimap zoom out", defaultMinimapZoomOut)
+		}
+		if !s.bindings.HasKeygroupsForName("zoom in") {
+			s.bindings.AddKeygroup("zoom in", defaultZoomIn)
+		}
+		if !s.bindings.HasKeygroupsForName("zoom out") {
+			s.bindings.AddKeygroup("zoom out", defaultZoomOut)
+		}
+		if !s.bindings.HasKeygroupsForName("camera north") {
+			s.bindings.AddKeygroup("camera north", defaultCameraNorth)
+		}
+		if !s.bindings.HasKeygroupsForName("camera south") {
+			s.bindings.AddKeygroup("camera south", defaultCameraSouth)
+		}
+		if !s.bindings.HasKeygroupsForName("camera west") {
+			s.bindings.AddKeygroup("camera west", defaultCameraWest)
+		}
+		if !s.bindings.HasKeygroupsForName("camera east") {
+			s.bindings.AddKeygroup("camera east", defaultCameraEast)
+		}
+		if !s.bindings.HasKeygroupsForName("center camera") {
+			s.bindings.AddKeygroup("center camera", defaultCenterCamera)
+		}
+		if !s.bindings.HasKeygroupsForName("mute") {
+			s.bindings.AddKeygroup("mute", defaultMute)
+		}
+		if !s.bindings.HasKeygroupsForName("volume up") {
+			s.bindings.AddKeygroup("volume up", defaultVolumeUp)
+		}
+		if !s.bindings.HasKeygroupsForName("volume down") {
+			s.bindings.AddKeygroup("volume down", defaultVolumeDown)
+		}
 	}
 }
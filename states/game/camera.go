@@ -0,0 +1,124 @@
+package game
+
+import (
+	"math"
+	"time"
+
+	"github.com/chimera-rpg/go-client/config"
+	"github.com/chimera-rpg/go-client/states/game/elements"
+	"github.com/chimera-rpg/go-client/ui"
+)
+
+const (
+	minObjectScale = 1.0
+	maxObjectScale = 8.0
+	zoomStep       = 1.25                   // Factor the scale changes by for each zoom step.
+	zoomDuration   = 150 * time.Millisecond // Time zooming takes to reach the new scale.
+)
+
+// SetupCamera points the map's scale and camera offset at the current character's settings.
+func (s *Game) SetupCamera() {
+	if s.Client.Replaying {
+		s.characterConfig = &config.CharacterConfig{}
+	} else {
+		s.characterConfig = s.Client.DataManager.Config.Server(s.Client.CurrentServer).GetCharacterConfig(s.characterName())
+	}
+	if s.characterConfig.ObjectScale <= 0 {
+		s.characterConfig.ObjectScale = s.Client.DataManager.Config.Game.Graphics.ObjectScale
+	}
+	s.objectsScale = &s.characterConfig.ObjectScale
+	// The scale flag overrides the config without replacing the saved scale.
+	if s.Client.Flags.GraphicsScale > 0 {
+		scale := s.Client.Flags.GraphicsScale
+		s.objectsScale = &scale
+	}
+	s.zoomTarget = *s.objectsScale
+}
+
+// Zoom changes the map's scale by the given number of steps, animating to it.
+func (s *Game) Zoom(steps int) {
+	target := math.Min(math.Max(s.zoomTarget*math.Pow(zoomStep, float64(steps)), minObjectScale), maxObjectScale)
+	if target == s.zoomTarget {
+		return
+	}
+	s.zoomFrom = *s.objectsScale
+	s.zoomTarget = target
+	s.zoomStart = time.Now()
+}
+
+// UpdateZoom advances the map's scale towards the zoom target, laying out every object again if it changed.
+func (s *Game) UpdateZoom() {
+	if *s.objectsScale == s.zoomTarget {
+		return
+	}
+	scale := s.zoomTarget
+	if t := float64(time.Since(s.zoomStart)) / float64(zoomDuration); t < 1 {
+		scale = s.zoomFrom + (s.zoomTarget-s.zoomFrom)*t
+	}
+	*s.objectsScale = scale
+	// Objects are placed anew at the new scale rather than sliding there.
+	for o := range s.slidingObjects {
+		s.stopSlide(o)
+	}
+	s.relayout = true
+	s.world.MarkAllObjectsChanged()
+}
+
+// PanCamera moves the camera away from the view object by the given amount of unscaled pixels.
+func (s *Game) PanCamera(x, y float64) {
+	s.characterConfig.CameraX += x
+	s.characterConfig.CameraY += y
+	s.cameraChanged = true
+}
+
+// CenterCamera moves the camera back onto the view object.
+func (s *Game) CenterCamera() {
+	s.characterConfig.CameraX = 0
+	s.characterConfig.CameraY = 0
+	s.cameraChanged = true
+}
+
+// DragCamera pans the camera while the middle mouse button is held on the map.
+func (s *Game) DragCamera(e elements.MouseInput) {
+	// The map window reports presses as not pressed and releases as pressed.
+	if !e.Pressed {
+		s.heldButtons[2] = true
+		s.dragX, s.dragY = e.X, e.Y
+	} else if !e.Held {
+		s.heldButtons[2] = false
+	}
+}
+
+// DragCameraTo pans the camera by how far the mouse moved since the last drag position.
+func (s *Game) DragCameraTo(x, y int32) {
+	scale := *s.objectsScale
+	s.PanCamera(float64(s.dragX-x)/scale, float64(s.dragY-y)/scale)
+	s.dragX, s.dragY = x, y
+}
+
+// cameraOffset returns how far the camera is from the view object in scaled pixels.
+func (s *Game) cameraOffset() (x, y float64) {
+	return s.characterConfig.CameraX * *s.objectsScale, s.characterConfig.CameraY * *s.objectsScale
+}
+
+// UpdateCamera scrolls the map to the camera's position, following the view object as it slides.
+func (s *Game) UpdateCamera(uiMessages *BatchMessages) {
+	if !s.cameraChanged {
+		return
+	}
+	s.cameraChanged = false
+	x, y := s.cameraOffset()
+	x += s.cameraX
+	y += s.cameraY
+	if o := s.world.GetViewObject(); o != nil {
+		x += o.SlideX
+		y += o.SlideY
+	}
+	uiMessages.add(ui.BatchUpdateMessage{
+		Target: &s.MapWindow.Container,
+		Update: ui.UpdateScroll{
+			Left: ui.Number{Value: x},
+			Top:  ui.Number{Value: y},
+		},
+	})
+}
@@ -66,6 +66,22 @@ func (s *Game) handleChatCommand(cmd string, args ...string) {
 				s.bindings.RunFunction(args[0])
 			}
 		}
+	case "cache":
+		stats, err := s.Client.DataManager.CacheStats()
+		if err != nil {
+			s.Print(fmt.Sprintf("couldn't read cache: %s", err))
+			return
+		}
+		if len(stats) == 0 {
+			s.Print("cache is empty")
+		}
+		for _, stat := range stats {
+			if stat.Namespace == s.Client.DataManager.Namespace() {
+				s.Print(stat.String() + " (active)")
+			} else {
+				s.Print(stat.String())
+			}
+		}
 	default:
 		if s.bindings.HasFunction(cmd) {
 			s.bindings.RunFunction(cmd, args)
@@ -2,6 +2,8 @@ package elements
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/chimera-rpg/go-client/ui"
 )
@@ -16,8 +18,13 @@ type DebugWindow struct {
 	selfLightInfo      ui.ElementI
 	underfootLightInfo ui.ElementI
 	blockedInfo        ui.ElementI
+	voiceInfo          ui.ElementI
+	voicesRefreshed    time.Time
 }
 
+// maxShownVoices is the number of voices listed in the voice readout.
+const maxShownVoices = 6
+
 func (c *DebugWindow) Setup(game game, style string, inputChan chan interface{}) (*ui.Container, error) {
 	c.game = game
 	var err error
@@ -81,7 +88,17 @@ func (c *DebugWindow) Setup(game game, style string, inputChan chan interface{})
 	c.container.GetAdoptChannel() <- c.tileLightInfo
 	c.container.GetAdoptChannel() <- c.selfLightInfo
 	c.container.GetAdoptChannel() <- c.underfootLightInfo
+	c.voiceInfo = ui.NewTextElement(ui.TextElementConfig{
+		Value: "",
+		Style: `
+			Y 72
+			ForegroundColor 255 255 255 255
+			OutlineColor 0 0 0 255
+		`,
+	})
+
 	c.container.GetAdoptChannel() <- c.blockedInfo
+	c.container.GetAdoptChannel() <- c.voiceInfo
 
 	return c.container, nil
 }
@@ -111,6 +128,26 @@ func (c *DebugWindow) Refresh() {
 		}
 	}
 	c.blockedInfo.GetUpdateChannel() <- ui.UpdateValue{Value: fmt.Sprintf("room %t, left open %t, above open %t, front open %t", c.game.World().InRoom, !c.game.World().LeftBlocked, !c.game.World().AboveBlocked, !c.game.World().FrontBlocked)}
+	c.voicesRefreshed = time.Time{}
+	c.RefreshVoices()
+}
+
+// RefreshVoices refreshes the readout of playing sounds. It is throttled so that it may be called every frame.
+func (c *DebugWindow) RefreshVoices() {
+	if !c.show || time.Since(c.voicesRefreshed) < 250*time.Millisecond {
+		return
+	}
+	c.voicesRefreshed = time.Now()
+	voices := c.game.Voices()
+	var parts []string
+	for i, v := range voices {
+		if i >= maxShownVoices {
+			parts = append(parts, "...")
+			break
+		}
+		parts = append(parts, v.String())
+	}
+	c.voiceInfo.GetUpdateChannel() <- ui.UpdateValue{Value: fmt.Sprintf("%d voices: %s", len(voices), strings.Join(parts, ", "))}
 }
 
 func (c *DebugWindow) Toggle() {
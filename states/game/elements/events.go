@@ -13,6 +13,11 @@ type MouseMoveInput struct {
 	X, Y int32
 }
 
+// MouseWheelInput is the UserInput for mouse wheel events.
+type MouseWheelInput struct {
+	X, Y int32
+}
+
 // FocusObject
 type FocusObjectEvent struct {
 	ID uint32
@@ -40,3 +45,8 @@ type ChatEvent struct {
 type InspectRequestEvent struct {
 	ID uint32
 }
+
+// MoveToEvent requests walking to the given tile.
+type MoveToEvent struct {
+	Y, X, Z int
+}
@@ -1,6 +1,7 @@
 package elements
 
 import (
+	"github.com/chimera-rpg/go-client/audio"
 	"github.com/chimera-rpg/go-client/config"
 	"github.com/chimera-rpg/go-client/ui"
 	"github.com/chimera-rpg/go-client/world"
@@ -21,4 +22,5 @@ type game interface {
 	Styles() map[string]map[string]string
 	Slot(uint32) string
 	TypeHint(uint32) string
+	Voices() []audio.VoiceInfo
 }
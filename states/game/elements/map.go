@@ -52,6 +52,13 @@ func (m *MapWindow) Setup(style string, inputChan chan interface{}) (ui.Containe
 				}
 				return true
 			},
+			OnMouseWheel: func(x, y int32) bool {
+				inputChan <- MouseWheelInput{
+					X: x,
+					Y: y,
+				}
+				return true
+			},
 			OnHold: func(buttonID uint8, x, y int32) bool {
 				inputChan <- MouseInput{
 					Button:  buttonID,
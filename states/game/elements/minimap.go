@@ -0,0 +1,299 @@
+package elements
+
+import (
+	"image"
+	"image/color"
+	"sync"
+	"time"
+
+	"github.com/chimera-rpg/go-client/ui"
+	"github.com/chimera-rpg/go-client/world"
+	cdata "github.com/chimera-rpg/go-server/data"
+)
+
+// minimapZooms are the pixels drawn per tile at each zoom level.
+var minimapZooms = []int{1, 2, 4, 8}
+
+const (
+	minimapRefreshInterval = 250 * time.Millisecond
+	minimapMinLight        = 0.3 // Portion of a tile's color kept when it is unlit, so that dark areas stay legible.
+	minimapHiddenLight     = 0.5 // Portion of a tile's color kept when it is not currently visible.
+	minimapMaxColors       = 4096
+)
+
+// Colors of the minimap's markers.
+var (
+	minimapViewColor     = color.NRGBA{255, 255, 255, 255}
+	minimapFocusColor    = color.NRGBA{0, 255, 255, 255}
+	minimapPCColor       = color.NRGBA{255, 255, 0, 255}
+	minimapNPCColor      = color.NRGBA{255, 64, 64, 255}
+	minimapFallbackColor = color.NRGBA{128, 128, 128, 255}
+	minimapBackground    = color.NRGBA{0, 0, 0, 0}
+)
+
+// MinimapWindow draws the current map top-down around the view object, with one tile per pixel at the lowest zoom.
+type MinimapWindow struct {
+	game      game
+	show      bool
+	container *ui.Container
+	image     ui.ElementI
+	refreshed time.Time
+	colors    map[image.Image]color.NRGBA // Average colors of object images.
+	lock      sync.Mutex                  // Guards the fields below, which are also used by UI events.
+	zoom      int                         // Index into minimapZooms.
+	originX   int                         // Tile X drawn at the left edge.
+	originZ   int                         // Tile Z drawn at the top edge.
+	objects   []uint32                    // Object to focus for each drawn tile, from left to right and top to bottom.
+	columns   int                         // Tiles drawn per row.
+}
+
+func (w *MinimapWindow) Setup(game game, style string, inputChan chan interface{}) (*ui.Container, error) {
+	w.game = game
+	w.show = true
+	w.colors = make(map[image.Image]color.NRGBA)
+	var err error
+	w.container, err = ui.NewContainerElement(ui.ContainerConfig{
+		Value: "Minimap",
+		Style: style,
+	})
+	if err != nil {
+		return nil, err
+	}
+	w.image = ui.NewImageElement(ui.ImageElementConfig{
+		Style: `
+			W 100%
+			H 100%
+		`,
+		Events: ui.Events{
+			OnPressed: func(buttonID uint8, x, y int32) bool {
+				if buttonID != 1 {
+					return true
+				}
+				if id := w.objectAt(x-w.image.GetAbsoluteX(), y-w.image.GetAbsoluteY()); id != 0 {
+					inputChan <- FocusObjectEvent{ID: id}
+				}
+				return false
+			},
+			OnMouseWheel: func(x, y int32) bool {
+				if y > 0 {
+					w.Zoom(1)
+				} else if y < 0 {
+					w.Zoom(-1)
+				}
+				return false
+			},
+		},
+	})
+	w.container.GetAdoptChannel() <- w.image
+
+	return w.container, nil
+}
+
+// Zoom changes the zoom level by the given number of levels.
+func (w *MinimapWindow) Zoom(delta int) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.zoom += delta
+	if w.zoom < 0 {
+		w.zoom = 0
+	} else if w.zoom >= len(minimapZooms) {
+		w.zoom = len(minimapZooms) - 1
+	}
+	w.refreshed = time.Time{}
+}
+
+// objectAt returns the object to focus for the given position within the minimap.
+func (w *MinimapWindow) objectAt(x, y int32) uint32 {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	scale := int32(minimapZooms[w.zoom])
+	if x < 0 || y < 0 || w.columns == 0 {
+		return 0
+	}
+	i := int(y/scale)*w.columns + int(x/scale)
+	if int(x/scale) >= w.columns || i >= len(w.objects) {
+		return 0
+	}
+	return w.objects[i]
+}
+
+// Refresh redraws the minimap. It is throttled so that it may be called every frame.
+func (w *MinimapWindow) Refresh() {
+	// The image is sent without holding the lock, as the UI's events may be waiting on it.
+	if img := w.draw(); img != nil {
+		w.image.GetUpdateChannel() <- ui.UpdateImage{Image: img}
+	}
+}
+
+// draw draws the minimap if it is due to be refreshed.
+func (w *MinimapWindow) draw() *image.NRGBA {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if !w.show || time.Since(w.refreshed) < minimapRefreshInterval {
+		return nil
+	}
+	w.refreshed = time.Now()
+
+	wld := w.game.World()
+	m := wld.GetCurrentMap()
+	vo := wld.GetViewObject()
+	width := int(w.image.GetWidth())
+	height := int(w.image.GetHeight())
+	if m == nil || vo == nil || width <= 0 || height <= 0 {
+		return nil
+	}
+
+	scale := minimapZooms[w.zoom]
+	w.columns = (width + scale - 1) / scale
+	rows := (height + scale - 1) / scale
+	w.originX = vo.X - w.columns/2
+	w.originZ = vo.Z - rows/2
+	w.objects = make([]uint32, w.columns*rows)
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	// Only tiles up to the view object's head are drawn so that roofs do not hide the rooms beneath them.
+	topY := vo.Y + int(vo.H) - 1
+	if topY >= m.GetHeight() {
+		topY = m.GetHeight() - 1
+	}
+	for row := 0; row < rows; row++ {
+		for col := 0; col < w.columns; col++ {
+			c := minimapBackground
+			x, z := w.originX+col, w.originZ+row
+			for y := topY; y >= 0; y-- {
+				t := m.GetTile(y, x, z)
+				if t == nil {
+					continue
+				}
+				if len(t.Objects()) == 0 {
+					// Tiles that are not known but were seen before are drawn from memory.
+					if o := wld.RememberedObject(y, x, z); o != nil {
+						c = w.rememberedColor(o)
+						break
+					}
+					continue
+				}
+				o := t.Objects()[len(t.Objects())-1]
+				c = w.tileColor(wld, t, o)
+				w.objects[row*w.columns+col] = o.ID
+				break
+			}
+			w.fill(img, col, row, c)
+		}
+	}
+
+	// Mark other characters, the focused object, and lastly the view object on top.
+	for _, o := range wld.ObjectsInBox(0, w.originX, w.originZ, m.GetHeight()-1, w.originX+w.columns-1, w.originZ+rows-1) {
+		if o == vo || !o.Visible || (o.Type != cdata.ArchetypePC.AsUint8() && o.Type != cdata.ArchetypeNPC.AsUint8()) {
+			continue
+		}
+		c := minimapNPCColor
+		if o.Type == cdata.ArchetypePC.AsUint8() {
+			c = minimapPCColor
+		}
+		w.mark(img, o, c, rows)
+	}
+	if o := wld.GetObject(w.game.FocusedObjectID()); o != nil && o != vo {
+		w.mark(img, o, minimapFocusColor, rows)
+	}
+	w.mark(img, vo, minimapViewColor, rows)
+
+	return img
+}
+
+// tileColor returns the color of the given object at the top of its tile, lit by the tile and dimmed if not visible.
+func (w *MinimapWindow) tileColor(wld *world.World, t *world.DynamicMapTile, o *world.Object) color.NRGBA {
+	c := w.objectColor(o)
+	r, g, b := t.FinalRGB()
+	shade := func(v uint8, light uint8) uint8 {
+		f := minimapMinLight + (1-minimapMinLight)*float64(light)/255
+		if !wld.IsTileVisible(o.Y, o.X, o.Z) {
+			f *= minimapHiddenLight
+		}
+		return uint8(float64(v) * f)
+	}
+	return color.NRGBA{shade(c.R, r), shade(c.G, g), shade(c.B, b), 255}
+}
+
+// rememberedColor returns the color of the given remembered object, greyed out like it is in the map.
+func (w *MinimapWindow) rememberedColor(o *world.Object) color.NRGBA {
+	c := w.objectColor(o)
+	l := uint8((0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)) * minimapHiddenLight)
+	return color.NRGBA{l, l, l, 255}
+}
+
+// objectColor returns the average color of the object's current image.
+func (w *MinimapWindow) objectColor(o *world.Object) color.NRGBA {
+	if o.Image == nil {
+		return minimapFallbackColor
+	}
+	if c, ok := w.colors[o.Image]; ok {
+		return c
+	}
+	var r, g, b, n uint64
+	bounds := o.Image.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			pr, pg, pb, pa := o.Image.At(x, y).RGBA()
+			if pa == 0 {
+				continue
+			}
+			r += uint64(pr >> 8)
+			g += uint64(pg >> 8)
+			b += uint64(pb >> 8)
+			n++
+		}
+	}
+	c := minimapFallbackColor
+	if n > 0 {
+		c = color.NRGBA{uint8(r / n), uint8(g / n), uint8(b / n), 255}
+	}
+	// Images that were unloaded and decoded again are new images, so the cache is dropped once it grows too large.
+	if len(w.colors) >= minimapMaxColors {
+		w.colors = make(map[image.Image]color.NRGBA)
+	}
+	w.colors[o.Image] = c
+	return c
+}
+
+// fill fills the pixels of the given tile.
+func (w *MinimapWindow) fill(img *image.NRGBA, col, row int, c color.NRGBA) {
+	scale := minimapZooms[w.zoom]
+	for py := row * scale; py < (row+1)*scale; py++ {
+		for px := col * scale; px < (col+1)*scale; px++ {
+			img.SetNRGBA(px, py, c)
+		}
+	}
+}
+
+// mark fills the tiles covered by the given object and makes it the one focused when they are clicked.
+func (w *MinimapWindow) mark(img *image.NRGBA, o *world.Object, c color.NRGBA, rows int) {
+	width, depth := int(o.W), int(o.D)
+	if width < 1 {
+		width = 1
+	}
+	if depth < 1 {
+		depth = 1
+	}
+	for x := o.X; x < o.X+width; x++ {
+		// Objects extend back in Z from their origin.
+		for z := o.Z; z > o.Z-depth; z-- {
+			col, row := x-w.originX, z-w.originZ
+			if col < 0 || row < 0 || col >= w.columns || row >= rows {
+				continue
+			}
+			w.fill(img, col, row, c)
+			w.objects[row*w.columns+col] = o.ID
+		}
+	}
+}
+
+// Toggle shows or hides the minimap.
+func (w *MinimapWindow) Toggle() {
+	w.lock.Lock()
+	w.show = !w.show
+	w.refreshed = time.Time{}
+	w.lock.Unlock()
+	w.container.GetUpdateChannel() <- ui.UpdateHidden(!w.show)
+	w.Refresh()
+}
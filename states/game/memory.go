@@ -0,0 +1,37 @@
+package game
+
+import (
+	"time"
+
+	"github.com/chimera-rpg/go-client/data"
+)
+
+const memorySaveInterval = 30 * time.Second // Time between writes of the current map's memory while playing.
+
+// characterName returns the name of the character being played.
+func (s *Game) characterName() string {
+	if s.Client.Flags.Character != "" {
+		return s.Client.Flags.Character
+	}
+	if sc, ok := s.Client.DataManager.Config.Servers[s.Client.CurrentServer]; ok {
+		return sc.Character
+	}
+	return ""
+}
+
+// SetupMapMemory points the world's map memory at the current server and character.
+func (s *Game) SetupMapMemory() {
+	s.world.SetMemoryPath(s.Client.DataManager.GetNamespacePath("maps", data.CacheNamespace(s.characterName())))
+	s.memorySaved = time.Now()
+}
+
+// SaveMapMemory writes the current map's memory if it is due to be saved or if force is set.
+func (s *Game) SaveMapMemory(force bool) {
+	if !force && time.Since(s.memorySaved) < memorySaveInterval {
+		return
+	}
+	s.memorySaved = time.Now()
+	if err := s.world.SaveMemory(); err != nil {
+		s.Client.Log.Warn("[Game] Could not save map memory: ", err)
+	}
+}
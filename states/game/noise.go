@@ -0,0 +1,80 @@
+package game
+
+import (
+	"time"
+
+	"github.com/chimera-rpg/go-client/audio"
+)
+
+// noiseTrackTime is how long a noise's occlusion is kept updated as the player moves.
+const noiseTrackTime = 10 * time.Second
+
+// noise is a recently played positional sound.
+type noise struct {
+	voiceID   uint32
+	y, x, z   int
+	occlusion int
+	started   time.Time
+}
+
+// PlayNoise plays the given sound positioned at the given tile relative to the view object.
+func (s *Game) PlayNoise(soundID uint32, volume float32, y, x, z int) {
+	s.UpdateListener()
+	s.lastVoiceID++
+	n := noise{
+		voiceID:   s.lastVoiceID,
+		y:         y,
+		x:         x,
+		z:         z,
+		occlusion: s.world.SoundOcclusion(y, x, z),
+		started:   time.Now(),
+	}
+	s.noises = append(s.noises, n)
+	s.sendAudio(audio.CommandPlaySound{
+		ID:         soundID,
+		Volume:     volume,
+		VoiceID:    n.voiceID,
+		Positional: true,
+		Y:          float64(y),
+		X:          float64(x),
+		Z:          float64(z),
+		Occlusion:  n.occlusion,
+	})
+}
+
+// UpdateListener moves the audio listener to the view object if it has moved, updating the occlusion of recent noises.
+func (s *Game) UpdateListener() {
+	o := s.world.GetViewObject()
+	if o == nil {
+		return
+	}
+	// Sounds are heard from the top of the view object.
+	listener := [3]int{int(o.Y) + int(o.H) - 1, int(o.X), int(o.Z)}
+	if s.hasListener && listener == s.listener {
+		return
+	}
+	s.listener = listener
+	s.hasListener = true
+
+	s.sendAudio(audio.CommandMoveListener{
+		Y: float64(listener[0]),
+		X: float64(listener[1]),
+		Z: float64(listener[2]),
+	})
+
+	noises := s.noises[:0]
+	for _, n := range s.noises {
+		if time.Since(n.started) > noiseTrackTime {
+			continue
+		}
+		if occlusion := s.world.SoundOcclusion(n.y, n.x, n.z); occlusion != n.occlusion {
+			n.occlusion = occlusion
+			s.sendAudio(audio.CommandOccludeSound{
+				VoiceID:   n.voiceID,
+				Occlusion: occlusion,
+			})
+		}
+		noises = append(noises, n)
+	}
+	s.noises = noises
+}
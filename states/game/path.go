@@ -0,0 +1,96 @@
+package game
+
+import (
+	"time"
+
+	"github.com/chimera-rpg/go-client/world"
+	cdata "github.com/chimera-rpg/go-server/data"
+	"github.com/chimera-rpg/go-server/network"
+)
+
+const (
+	pathStepTimeout = 2 * time.Second // Time to wait for a step to complete before planning again.
+	maxPathReplans  = 4               // Number of times in a row a path may be planned again without a step completing.
+)
+
+// pathWalk is a path being walked by the view object.
+type pathWalk struct {
+	y, x, z     int // Goal position.
+	steps       []world.PathStep
+	from        [3]int    // Position the current step was sent from.
+	sent        time.Time // Time the current step was sent, zero if no step is pending.
+	tileChanges uint64    // World tile changes the path was planned for.
+	replans     int
+}
+
+// flying returns if the view object can move up and down freely.
+func (s *Game) flying() bool {
+	return s.statuses[cdata.FlyingStatus] || s.statuses[cdata.SwimmingStatus]
+}
+
+// MoveTo walks the view object to the given position.
+func (s *Game) MoveTo(y, x, z int) {
+	s.path = &pathWalk{y: y, x: x, z: z}
+	if !s.planPath() {
+		s.Print("You see no way to get there.")
+		s.path = nil
+	}
+}
+
+// CancelPath stops walking the current path.
+func (s *Game) CancelPath() {
+	s.path = nil
+}
+
+// planPath plans the current path from the view object's position, returning false if there is no way closer to the goal.
+func (s *Game) planPath() bool {
+	p := s.path
+	p.steps = s.world.FindPath(s.world.GetViewObject(), p.y, p.x, p.z, s.flying())
+	p.sent = time.Time{}
+	p.tileChanges = s.world.TileChanges()
+	p.replans++
+	return len(p.steps) > 0 && p.replans <= maxPathReplans
+}
+
+// UpdatePath sends the next step of the current path once the previous one has completed, planning the path again if the map changed or the view object drifted off of it.
+func (s *Game) UpdatePath() {
+	p := s.path
+	vo := s.world.GetViewObject()
+	if p == nil || vo == nil {
+		return
+	}
+	// Let falls finish before deciding where we are.
+	if s.statuses[cdata.FallingStatus] {
+		return
+	}
+	pos := [3]int{vo.Y, vo.X, vo.Z}
+	if !p.sent.IsZero() {
+		step := p.steps[0]
+		if pos == [3]int{step.Y, step.X, step.Z} {
+			p.steps = p.steps[1:]
+			p.sent = time.Time{}
+			p.replans = 0
+		} else if pos == p.from && time.Since(p.sent) < pathStepTimeout {
+			return
+		} else if !s.planPath() {
+			s.path = nil
+			return
+		}
+	}
+	if len(p.steps) == 0 {
+		s.path = nil
+		return
+	}
+	if p.tileChanges != s.world.TileChanges() {
+		if !s.planPath() {
+			s.path = nil
+			return
+		}
+	}
+	s.runDirection = p.steps[0].Direction
+	s.Client.Send(network.CommandCmd{
+		Cmd: p.steps[0].Direction,
+	})
+	p.from = pos
+	p.sent = time.Now()
+}
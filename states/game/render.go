@@ -39,6 +39,7 @@ func (b *BatchMessages) add(m ui.BatchMessage) {
 // HandleRender handles the rendering of our Game state.
 func (s *Game) HandleRender(delta time.Duration) {
 	var batchMessages BatchMessages
+	s.UpdateZoom()
 	ctx := s.GetRenderContext()
 	// FIXME: This is _very_ rough and is just for testing!
 	m := s.world.GetCurrentMap()
@@ -66,13 +67,8 @@ func (s *Game) HandleRender(delta time.Duration) {
 		x -= float64(s.MapWindow.Container.GetWidth()) / 2
 		y -= float64(s.MapWindow.Container.GetHeight()) / 2
 
-		batchMessages.add(ui.BatchUpdateMessage{
-			Target: &s.MapWindow.Container,
-			Update: ui.UpdateScroll{
-				Left: ui.Number{Value: x},
-				Top:  ui.Number{Value: y},
-			},
-		})
+		s.cameraX, s.cameraY = x, y
+		s.cameraChanged = true
 
 		s.RenderObject(ctx, viewObject, o, m, delta, &batchMessages)
 	}
@@ -105,6 +101,11 @@ func (s *Game) HandleRender(delta time.Duration) {
 
 	s.world.ClearChangedObjects()
 
+	s.relayout = false
+
+	s.UpdateSlides(&batchMessages)
+	s.UpdateCamera(&batchMessages)
+
 	// Iterate over world messages.
 	now := time.Now()
 	for i := len(s.MapWindow.Messages) - 1; i >= 0; i-- {
@@ -127,18 +128,7 @@ func (s *Game) HandleRender(delta time.Duration) {
 					y := o.Y + int(o.H) + 1
 					z := o.Z
 					xPos, yPos, _ := s.GetRenderPosition(ctx, s.world.GetCurrentMap(), y, x, z)
-					batchMessages.add(ui.BatchUpdateMessage{
-						Target: msg.El,
-						Update: ui.UpdateX{
-							Number: ui.Number{Value: float64(xPos)},
-						},
-					})
-					batchMessages.add(ui.BatchUpdateMessage{
-						Target: msg.El,
-						Update: ui.UpdateY{
-							Number: ui.Number{Value: float64(yPos)},
-						},
-					})
+					s.moveSlidingElement(msg.El, xPos, yPos, o, &batchMessages)
 				}
 			}
 			// Move message upwards if need be.
@@ -242,6 +232,10 @@ func (s *Game) RenderObject(ctx RenderContext, viewObject *world.Object, o *worl
 	if o.Contained {
 		return
 	}
+	// Skip remembered objects that were forgotten before they were first rendered.
+	if o.Remembered && s.world.RememberedObject(o.Y, o.X, o.Z) != o {
+		return
+	}
 	if o != viewObject {
 		if o.Element != nil {
 			if o.Missing && o.WasMissing {
@@ -271,8 +265,13 @@ func (s *Game) RenderObject(ctx RenderContext, viewObject *world.Object, o *worl
 
 	// Get and cache our render position.
 	if o.Changed {
+		renderX, renderY := o.RenderX, o.RenderY
 		o.RenderX, o.RenderY, o.RenderZ = s.GetObjectRenderPosition(ctx, m, o)
 		o.RecalculateFinalRender = true
+		// Slide from where the object is currently shown if it was already on the map.
+		if o.Element != nil && !s.relayout && (renderX != o.RenderX || renderY != o.RenderY) {
+			s.startSlide(ctx, o, float64(renderX-o.RenderX)+o.SlideX, float64(renderY-o.RenderY)+o.SlideY)
+		}
 	}
 
 	// Acquire and cache our object's adjustment.
@@ -333,6 +332,7 @@ func (s *Game) RenderObjectImage(ctx RenderContext, o *world.Object, m *world.Dy
 	}
 
 	if o.Element == nil {
+		o.ElementX, o.ElementY = x, y
 		o.Element = ui.NewImageElement(ui.ImageElementConfig{
 			Style: fmt.Sprintf(`
 							X %d
@@ -345,16 +345,21 @@ func (s *Game) RenderObjectImage(ctx RenderContext, o *world.Object, m *world.Dy
 			PostOutline: true,
 			Events: ui.Events{
 				OnPressed: func(button uint8, x, y int32) bool {
-					if button != 1 {
+					if button != 1 || o.Remembered {
 						return true
 					}
 					// Ignore elements with an alpha less than or equal 0.1.
 					if o.Element.GetStyle().Alpha.Value <= 0.1 {
 						return true
 					}
-					// Ignore elements that are blocks or tiles.
+					// Clicking blocks or tiles walks on top of them rather than focusing them.
 					// TODO: Ignore if shift is held.
 					if o.Type == cdata.ArchetypeBlock.AsUint8() || o.Type == cdata.ArchetypeTile.AsUint8() {
+						if o.Element.PixelHit(x, y) {
+							ty, tx, tz := o.StandingPosition()
+							s.inputChan <- elements.MoveToEvent{Y: ty, X: tx, Z: tz}
+							return false
+						}
 						return true
 					}
 					if o.Element.PixelHit(x, y) {
@@ -364,7 +369,7 @@ func (s *Game) RenderObjectImage(ctx RenderContext, o *world.Object, m *world.Dy
 					return true
 				},
 				OnMouseMove: func(x int32, y int32) bool {
-					if o.Element.GetStyle().Alpha.Value <= 0.1 {
+					if o.Remembered || o.Element.GetStyle().Alpha.Value <= 0.1 {
 						return true
 					}
 					// Ignore elements that are blocks or tiles.
@@ -379,6 +384,9 @@ func (s *Game) RenderObjectImage(ctx RenderContext, o *world.Object, m *world.Dy
 					return true
 				},
 				OnMouseOut: func(x int32, y int32) bool {
+					if o.Remembered {
+						return true
+					}
 					// Always unhover if the mouse leaves the object.
 					s.inputChan <- elements.UnhoverObjectEvent{ID: o.ID}
 					return true
@@ -452,11 +460,12 @@ func (s *Game) RenderObjectImage(ctx RenderContext, o *world.Object, m *world.Dy
 				y -= int(sh) - ctx.tileHeightScaled
 			}
 
+			o.ElementX, o.ElementY = x, y
 			uiMessages.add(ui.BatchUpdateMessage{
 				Target: o.Element,
 				Update: ui.UpdateDimensions{
-					X: ui.Number{Value: float64(x)},
-					Y: ui.Number{Value: float64(y)},
+					X: ui.Number{Value: float64(x) + o.SlideX},
+					Y: ui.Number{Value: float64(y) + o.SlideY},
 					W: ui.Number{Value: sw},
 					H: ui.Number{Value: sh},
 				},
@@ -507,6 +516,7 @@ func (s *Game) RenderObjectShadows(ctx RenderContext, o *world.Object, m *world.
 		y += rh / 2
 	}
 
+	o.ShadowX, o.ShadowY = x, y
 	if o.ShadowElement == nil {
 		o.ShadowElement = ui.NewPrimitiveElement(ui.PrimitiveElementConfig{
 			Shape: ui.EllipseShape,
@@ -529,8 +539,8 @@ func (s *Game) RenderObjectShadows(ctx RenderContext, o *world.Object, m *world.
 			uiMessages.add(ui.BatchUpdateMessage{
 				Target: o.ShadowElement,
 				Update: ui.UpdateDimensions{
-					X: ui.Number{Value: float64(x)},
-					Y: ui.Number{Value: float64(y)},
+					X: ui.Number{Value: float64(x) + o.SlideX},
+					Y: ui.Number{Value: float64(y) + o.SlideY},
 					W: ui.Number{Value: float64(w)},
 					H: ui.Number{Value: float64(h)},
 				},
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"math"
+	"time"
+
+	"github.com/chimera-rpg/go-client/ui"
+	"github.com/chimera-rpg/go-client/world"
+)
+
+const (
+	DefaultMoveDuration = 100 // Milliseconds objects take to slide to a new tile if none is configured.
+	slideSnapTiles      = 3   // Moves farther than this many tiles snap into place rather than sliding.
+)
+
+// moveDuration returns how long objects take to slide to a new tile, or 0 if sliding is disabled.
+func (s *Game) moveDuration() time.Duration {
+	ms := s.Client.DataManager.Config.Game.Graphics.MoveDuration
+	if ms < 0 {
+		return 0
+	} else if ms == 0 {
+		ms = DefaultMoveDuration
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// startSlide starts sliding the given object from where it is currently shown to its new render position, which is dx and dy pixels away. Moves beyond the snap distance, such as teleports, place the object immediately.
+func (s *Game) startSlide(ctx RenderContext, o *world.Object, dx, dy float64) {
+	if s.moveDuration() == 0 || (dx == 0 && dy == 0) || math.Hypot(dx, dy) > float64(slideSnapTiles*ctx.tileWidthScaled) {
+		s.stopSlide(o)
+		return
+	}
+	o.SlideFromX, o.SlideFromY = dx, dy
+	o.SlideX, o.SlideY = dx, dy
+	o.SlideStart = time.Now()
+	s.slidingObjects[o] = struct{}{}
+}
+
+// stopSlide places the given object at its render position.
+func (s *Game) stopSlide(o *world.Object) {
+	o.SlideX, o.SlideY = 0, 0
+	delete(s.slidingObjects, o)
+}
+
+// UpdateSlides advances sliding objects and moves their elements and shadows to match.
+func (s *Game) UpdateSlides(uiMessages *BatchMessages) {
+	duration := s.moveDuration()
+	for o := range s.slidingObjects {
+		if o.Element == nil {
+			s.stopSlide(o)
+			continue
+		}
+		t := float64(time.Since(o.SlideStart)) / float64(duration)
+		if duration == 0 || t >= 1 {
+			s.stopSlide(o)
+		} else {
+			o.SlideX = o.SlideFromX * (1 - t)
+			o.SlideY = o.SlideFromY * (1 - t)
+		}
+		if o == s.world.GetViewObject() {
+			s.cameraChanged = true
+		}
+		s.moveSlidingElement(o.Element, o.ElementX, o.ElementY, o, uiMessages)
+		if o.ShadowElement != nil {
+			s.moveSlidingElement(o.ShadowElement, o.ShadowX, o.ShadowY, o, uiMessages)
+		}
+	}
+}
+
+// moveSlidingElement places an element of the given object at its position plus the object's slide offset.
+func (s *Game) moveSlidingElement(e ui.ElementI, x, y int, o *world.Object, uiMessages *BatchMessages) {
+	uiMessages.add(ui.BatchUpdateMessage{
+		Target: e,
+		Update: ui.UpdateX{
+			Number: ui.Number{Value: float64(x) + o.SlideX},
+		},
+	})
+	uiMessages.add(ui.BatchUpdateMessage{
+		Target: e,
+		Update: ui.UpdateY{
+			Number: ui.Number{Value: float64(y) + o.SlideY},
+		},
+	})
+}
@@ -24,6 +24,16 @@ BackgroundColor 0 0 0 0
 ZIndex 1
 `
 
+var MinimapWindowStyle string = `
+	X 100%
+	Y 0
+	W 200
+	H 200
+	Origin Right
+	BackgroundColor 0 0 0 160
+	ZIndex 2
+`
+
 var ChatWindowStyle string = `
 	X 60%
 	Y 0
@@ -35,7 +35,7 @@ func (s *Game) SetupUI() (err error) {
 	if s.Client.DataManager.Config.Game.Graphics.ObjectScale == 0 {
 		s.Client.DataManager.Config.Game.Graphics.ObjectScale = 4
 	}
-	s.objectsScale = &s.Client.DataManager.Config.Game.Graphics.ObjectScale
+	s.SetupCamera()
 	fmt.Println("objectsScale", *s.objectsScale)
 	// Main Container
 	err = s.GameContainer.Setup(ui.ContainerConfig{
@@ -162,6 +162,16 @@ func (s *Game) SetupUI() (err error) {
 		panic(err)
 	}
 	s.GameContainer.AdoptChannel <- debugContainer.This
+	// Sub-window: minimap
+	minimapStyle, ok := s.Styles()["Game"]["Minimap"]
+	if !ok {
+		minimapStyle = MinimapWindowStyle
+	}
+	minimapContainer, err := s.MinimapWindow.Setup(s, minimapStyle, s.inputChan)
+	if err != nil {
+		panic(err)
+	}
+	s.GameContainer.AdoptChannel <- minimapContainer.This
 
 	// Sub-window: stats
 	err = s.StatsWindow.Setup(ui.ContainerConfig{
@@ -229,7 +239,7 @@ func (s *Game) UpdateMessagesWindow() {
 				addMessage(fmt.Sprintf("[%s] %s: %s", msgName, m.Message.From, m.Message.Body))
 			} else if m.Message.Type == network.TargetMessage {
 				// Target messages get printed plainly.
-				if m.Message.FromObjectID != s.world.GetViewObject().ID {
+				if vo := s.world.GetViewObject(); vo == nil || m.Message.FromObjectID != vo.ID {
 					n := "???"
 					o := s.world.GetObject(m.Message.FromObjectID)
 					if o != nil {
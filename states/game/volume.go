@@ -0,0 +1,57 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chimera-rpg/go-client/audio"
+)
+
+// volumeStep is how much the volume bindings change a bus's volume.
+const volumeStep = 0.1
+
+// busFromArgs returns the bus named in the arguments of a bind function, defaulting to the master bus.
+func busFromArgs(i []interface{}) audio.Bus {
+	if len(i) > 0 {
+		if args, ok := i[0].([]string); ok && len(args) > 0 {
+			name := strings.TrimSpace(args[0])
+			for _, bus := range audio.Buses {
+				if bus.String() == name {
+					return bus
+				}
+			}
+		}
+	}
+	return audio.BusMaster
+}
+
+// ChangeVolume changes the volume of the given bus by delta, storing it in the audio config.
+func (s *Game) ChangeVolume(bus audio.Bus, delta float64) {
+	conf := audio.BusConfig(&s.Client.DataManager.Config.Audio, bus)
+	conf.Volume += delta
+	if conf.Volume < 0 {
+		conf.Volume = 0
+	} else if conf.Volume > 1 {
+		conf.Volume = 1
+	}
+	s.sendAudio(audio.CommandSetVolume{
+		Bus:    bus,
+		Volume: conf.Volume,
+	})
+	s.Print(fmt.Sprintf("%s volume: %.0f%%", bus, conf.Volume*100))
+}
+
+// ToggleMute mutes or unmutes the given bus, storing it in the audio config.
+func (s *Game) ToggleMute(bus audio.Bus) {
+	conf := audio.BusConfig(&s.Client.DataManager.Config.Audio, bus)
+	conf.Muted = !conf.Muted
+	s.sendAudio(audio.CommandSetMute{
+		Bus:   bus,
+		Muted: conf.Muted,
+	})
+	if conf.Muted {
+		s.Print(fmt.Sprintf("%s muted", bus))
+	} else {
+		s.Print(fmt.Sprintf("%s unmuted", bus))
+	}
+}
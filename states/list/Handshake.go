@@ -1,11 +1,16 @@
 package list
 
 import (
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/chimera-rpg/go-client/client"
+	"github.com/chimera-rpg/go-client/states/game"
 	"github.com/chimera-rpg/go-client/states/login"
 	"github.com/chimera-rpg/go-client/ui"
 	"github.com/chimera-rpg/go-server/network"
@@ -16,8 +21,27 @@ import (
 type Handshake struct {
 	client.State
 	ServersWindow ui.Window
+	Resume        *game.Resume // If set, the connection is retried with backoff and login proceeds automatically.
+	prompt        ui.ElementI
 }
 
+// FingerprintError is returned when a server presents a certificate that does not match its pinned fingerprint.
+type FingerprintError struct {
+	Server      string
+	Pinned      string
+	Fingerprint string
+}
+
+func (e *FingerprintError) Error() string {
+	return fmt.Sprintf("certificate of \"%s\" changed from %s to %s", e.Server, e.Pinned, e.Fingerprint)
+}
+
+const (
+	reconnectAttempts = 8
+	reconnectDelay    = 1 * time.Second
+	reconnectMaxDelay = 30 * time.Second
+)
+
 // Init Handshake
 func (s *Handshake) Init(v interface{}) (state client.StateI, nextArgs interface{}, err error) {
 	go func() {
@@ -31,42 +55,43 @@ func (s *Handshake) Init(v interface{}) (state client.StateI, nextArgs interface
 		}()
 		server, ok := v.(string)
 		if ok == false {
-			msg := fmt.Sprintf("Bad server value.")
-			s.Client.Log.Print(msg)
-			s.Client.StateChannel <- client.StateMessage{Pop: true, Args: msg}
+			s.fail(errors.New("Bad server value."))
 			return
 		}
 
-		// There should be some sort of way to detect secure vs. insecure hosts. Perhaps this would just be through the server list service? Otherwise, we could have an "info" port where server information is queried.
-		err = s.Client.SecureConnectTo(server, &tls.Config{
-			InsecureSkipVerify: true, // Skip verification for now. In the future this will be configurable.
-		})
-		if err != nil {
+		s.Client.CurrentServer = server
+
+		if s.Resume != nil {
+			err = s.reconnect(server)
+		} else {
+			err = s.connect(server)
+		}
+		var fingerprintErr *FingerprintError
+		for errors.As(err, &fingerprintErr) && !s.Client.Flags.NonInteractive() {
 			s.Client.Log.Print(err)
-			// For now, just fall back to attempting an insecure connection.
-			s.Client.Log.Print("Falling back to insecure connection.")
-			err = s.Client.ConnectTo(server)
-			if err != nil {
-				s.Client.Log.Print(err)
-				s.Client.StateChannel <- client.StateMessage{Pop: true, Args: err}
-				return
+			if !s.trustPrompt(fingerprintErr) {
+				break
 			}
+			s.Client.DataManager.Config.Server(server).Fingerprint = fingerprintErr.Fingerprint
+			s.writeConfig()
+			err = s.connect(server)
 		}
+		if err != nil {
+			s.fail(err)
+			return
+		}
+		s.Client.SetCacheNamespace(server)
 
 		select {
 		case cmd := <-s.Client.CmdChan:
 			switch cmd.(type) {
 			case network.CommandHandshake:
 			default:
-				msg := fmt.Sprintf("Server \"%s\" sent non-handshake..", server)
-				s.Client.Log.Print(msg)
-				s.Client.StateChannel <- client.StateMessage{Pop: true, Args: msg}
+				s.fail(fmt.Errorf("Server \"%s\" sent non-handshake..", server))
 				return
 			}
 		case <-time.After(2 * time.Second):
-			msg := fmt.Sprintf("Server \"%s\" took too long to respond.", server)
-			s.Client.Log.Printf(msg)
-			s.Client.StateChannel <- client.StateMessage{Pop: true, Args: msg}
+			s.fail(fmt.Errorf("Server \"%s\" took too long to respond.", server))
 			return
 		}
 
@@ -84,14 +109,177 @@ func (s *Handshake) Init(v interface{}) (state client.StateI, nextArgs interface
 			s.Client.Slots = t.Slots
 			s.Client.TypeHints = t.TypeHints
 		default:
-			msg := fmt.Sprintf("Server \"%s\" sent non CommandFeatures.", server)
-			s.Client.Log.Print(msg)
-			s.Client.StateChannel <- client.StateMessage{Pop: true, Args: msg}
+			s.fail(fmt.Errorf("Server \"%s\" sent non CommandFeatures.", server))
 			return
 		}
 
-		s.Client.StateChannel <- client.StateMessage{State: &login.Login{}}
+		s.Client.StateChannel <- client.StateMessage{State: &login.Login{Resume: s.Resume}}
 	}()
 
 	return
 }
+
+// fail returns to the server list with the given error, or stops the client if logging in non-interactively.
+func (s *Handshake) fail(err error) {
+	s.Client.Log.Print(err)
+	if s.Client.Flags.NonInteractive() {
+		s.Client.Fail(err)
+		return
+	}
+	s.Client.StateChannel <- client.StateMessage{Pop: true, Args: err}
+}
+
+// connect connects to the server over TLS, pinning the server's certificate on first use. If TLS fails, an insecure connection is attempted unless the server requires TLS or has a pinned certificate.
+func (s *Handshake) connect(server string) (err error) {
+	sc := s.Client.DataManager.Config.Server(server)
+	var fingerprint string
+	err = s.Client.SecureConnectTo(server, &tls.Config{
+		// Servers generally use self-signed certificates, so we trust the first certificate we see and verify against it afterwards.
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return errors.New("server presented no certificate")
+			}
+			sum := sha256.Sum256(rawCerts[0])
+			fingerprint = hex.EncodeToString(sum[:])
+			if sc.Fingerprint != "" && sc.Fingerprint != fingerprint {
+				return &FingerprintError{
+					Server:      server,
+					Pinned:      sc.Fingerprint,
+					Fingerprint: fingerprint,
+				}
+			}
+			return nil
+		},
+	})
+	if err == nil {
+		if sc.Fingerprint == "" {
+			s.Client.Log.Printf("Pinning certificate of \"%s\": %s", server, fingerprint)
+			sc.Fingerprint = fingerprint
+			s.writeConfig()
+		}
+		return
+	}
+	var fingerprintErr *FingerprintError
+	if errors.As(err, &fingerprintErr) {
+		return
+	}
+	s.Client.Log.Print(err)
+	// A pinned server has spoken TLS before, so failing to now may be an attacker stripping it.
+	if sc.RequireTLS || sc.Fingerprint != "" {
+		return fmt.Errorf("secure connection to \"%s\" failed and insecure connections are not allowed: %w", server, err)
+	}
+	s.Client.Log.Print("Falling back to insecure connection.")
+	return s.Client.ConnectTo(server)
+}
+
+// writeConfig saves the config so that a newly pinned fingerprint survives the client exiting early.
+func (s *Handshake) writeConfig() {
+	if err := s.Client.DataManager.Config.Write(); err != nil {
+		s.Client.Log.Print(err)
+	}
+}
+
+// trustPrompt asks the user whether the server's new certificate should be trusted, blocking until a choice is made.
+func (s *Handshake) trustPrompt(fingerprintErr *FingerprintError) bool {
+	choice := make(chan bool, 1)
+
+	container, err := ui.NewContainerElement(ui.ContainerConfig{
+		Value: "Certificate Changed",
+		Style: `
+			X 50%
+			Y 50%
+			W 420
+			H 160
+			Origin CenterX CenterY
+			BackgroundColor 32 32 32 240
+		`,
+	})
+	if err != nil {
+		s.Client.Log.Print(err)
+		return false
+	}
+	text := ui.NewTextElement(ui.TextElementConfig{
+		Value: fmt.Sprintf("The certificate of \"%s\" has changed!\nPinned: %s\nNew: %s\nThis may mean someone is intercepting your connection. Only trust the new certificate if you know the server changed it.", fingerprintErr.Server, fingerprintErr.Pinned, fingerprintErr.Fingerprint),
+		Style: `
+			X 8
+			Y 8
+			W 404
+			ForegroundColor 255 255 255 255
+		`,
+	})
+	trustButton := ui.NewButtonElement(ui.ButtonElementConfig{
+		Value: "TRUST",
+		Style: `
+			X 8
+			Y 8
+			W 100
+			Origin Bottom
+		`,
+		Events: ui.Events{
+			OnPressed: func(button uint8, x, y int32) bool {
+				choice <- true
+				return false
+			},
+		},
+	})
+	cancelButton := ui.NewButtonElement(ui.ButtonElementConfig{
+		Value: "CANCEL",
+		Style: `
+			X 8
+			Y 8
+			W 100
+			Origin Right Bottom
+		`,
+		Events: ui.Events{
+			OnPressed: func(button uint8, x, y int32) bool {
+				choice <- false
+				return false
+			},
+		},
+	})
+	container.GetAdoptChannel() <- text
+	container.GetAdoptChannel() <- trustButton
+	container.GetAdoptChannel() <- cancelButton
+	s.prompt = container
+	s.Client.RootWindow.AdoptChannel <- s.prompt
+
+	defer s.Close()
+
+	select {
+	case trusted := <-choice:
+		return trusted
+	case <-s.CloseChan:
+		return false
+	}
+}
+
+// Close removes the trust prompt if it is still shown.
+func (s *Handshake) Close() {
+	if s.prompt != nil {
+		s.prompt.GetDestroyChannel() <- true
+		s.prompt = nil
+	}
+}
+
+// reconnect repeatedly attempts to connect to the server, doubling the delay between attempts.
+func (s *Handshake) reconnect(server string) (err error) {
+	delay := reconnectDelay
+	for attempt := 1; attempt <= reconnectAttempts; attempt++ {
+		var fingerprintErr *FingerprintError
+		if err = s.connect(server); err == nil || errors.As(err, &fingerprintErr) {
+			return
+		}
+		s.Client.Log.Printf("Reconnect attempt %d/%d failed, retrying in %s", attempt, reconnectAttempts, delay)
+		select {
+		case <-time.After(delay):
+		case <-s.CloseChan:
+			return errors.New("reconnect cancelled")
+		}
+		delay *= 2
+		if delay > reconnectMaxDelay {
+			delay = reconnectMaxDelay
+		}
+	}
+	return fmt.Errorf("could not reconnect to \"%s\": %w", server, err)
+}
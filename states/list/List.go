@@ -2,6 +2,7 @@ package list
 
 import (
 	"github.com/chimera-rpg/go-client/client"
+	"github.com/chimera-rpg/go-client/states/game"
 	"github.com/chimera-rpg/go-client/ui"
 )
 
@@ -85,4 +86,12 @@ func (s *List) Leave() {
 
 func (s *List) Enter(args ...interface{}) {
 	s.layout.Find("List").Element.GetUpdateChannel() <- ui.UpdateHidden(false)
+	// Begin reconnecting if the game lost its connection.
+	if len(args) > 0 {
+		if resume, ok := args[0].(*game.Resume); ok {
+			go func() {
+				s.Client.StateChannel <- client.StateMessage{Push: true, State: &Handshake{Resume: resume}, Args: s.Client.CurrentServer}
+			}()
+		}
+	}
 }
@@ -724,7 +724,8 @@ func (s *CharacterCreation) Loop() {
 			if ret {
 				return
 			}
-		case <-s.Client.DataManager.UpdatedImageIDs:
+		case id := <-s.Client.DataManager.UpdatedImageIDs:
+			s.Client.RefreshImage(id)
 			s.refreshImages()
 			// TODO: Refresh genus/species/pc image
 		case <-s.Client.ClosedChan:
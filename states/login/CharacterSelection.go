@@ -1,7 +1,8 @@
 package login
 
 import (
-	"flag"
+	"errors"
+	"fmt"
 
 	"github.com/chimera-rpg/go-client/client"
 	"github.com/chimera-rpg/go-client/states/game"
@@ -15,6 +16,7 @@ type CharacterSelection struct {
 	client.State
 	layout ui.LayoutEntry
 	bail   chan bool
+	Resume *game.Resume // If set, we automatically select the stored character.
 }
 
 // Init is our CharacterSelection init state.
@@ -108,10 +110,13 @@ func (s *CharacterSelection) Enter(args ...interface{}) {
 // Loop is our loop for managing network activity and beyond.
 func (s *CharacterSelection) Loop() {
 	// Attempt to use provided character.
-	character := flag.Lookup("character")
-	if character.Value.String() != character.DefValue {
+	if s.Client.Flags.Character != "" {
 		s.Client.Send(network.Command(network.CommandSelectCharacter{
-			Name: character.Value.String(),
+			Name: s.Client.Flags.Character,
+		}))
+	} else if s.Resume != nil {
+		s.Client.Send(network.Command(network.CommandSelectCharacter{
+			Name: s.Client.DataManager.Config.Servers[s.Client.CurrentServer].Character,
 		}))
 	}
 
@@ -122,8 +127,8 @@ func (s *CharacterSelection) Loop() {
 		select {
 		case <-s.bail:
 			return
-		case <-s.Client.DataManager.UpdatedImageIDs:
-			// TODO: Refresh character image
+		case id := <-s.Client.DataManager.UpdatedImageIDs:
+			s.Client.RefreshImage(id)
 		case cmd := <-s.Client.CmdChan:
 			ret := s.HandleNet(cmd)
 			if ret {
@@ -131,6 +136,10 @@ func (s *CharacterSelection) Loop() {
 			}
 		case <-s.Client.ClosedChan:
 			s.Client.Log.Print("Lost connection to server.")
+			if s.Client.Flags.NonInteractive() {
+				s.Client.Fail(errors.New("lost connection to server while selecting a character"))
+				return
+			}
 			s.Client.StateChannel <- client.StateMessage{PopToTop: true, Args: nil}
 			return
 		}
@@ -147,20 +156,24 @@ func (s *CharacterSelection) HandleNet(cmd network.Command) bool {
 	case network.CommandBasic:
 		if t.Type == network.Reject {
 			s.Client.Log.Printf("Server rejected us: %s\n", t.String)
+			if s.Client.Flags.NonInteractive() {
+				s.Client.Fail(fmt.Errorf("could not select character \"%s\": %s", s.Client.Flags.Character, t.String))
+				return true
+			}
 		} else if t.Type == network.Okay {
 			s.Client.Log.Printf("Server accepted us: %s\n", t.String)
 			// Might as well save the configuration now.
 			if err := s.Client.DataManager.Config.Write(); err != nil {
 				s.Client.Log.Errorln(err)
 			}
-			s.Client.StateChannel <- client.StateMessage{Push: true, State: &game.Game{}, Args: nil}
+			s.Client.StateChannel <- client.StateMessage{Push: true, State: &game.Game{}, Args: s.Resume}
 			return true
 		}
 	case network.CommandCharacter:
 		s.addCharacter(t.Name)
 	case network.CommandSelectCharacter:
 		// SelectCharacter is how the server lets us know we're logging in as a character.
-		s.Client.StateChannel <- client.StateMessage{Push: true, State: &game.Game{}, Args: nil}
+		s.Client.StateChannel <- client.StateMessage{Push: true, State: &game.Game{}, Args: s.Resume}
 		// Might as well save the configuration now.
 		if err := s.Client.DataManager.Config.Write(); err != nil {
 			s.Client.Log.Errorln(err)
@@ -168,6 +181,10 @@ func (s *CharacterSelection) HandleNet(cmd network.Command) bool {
 		return true
 	default:
 		s.Client.Log.Printf("Server sent non CommandBasic\n")
+		if s.Client.Flags.NonInteractive() {
+			s.Client.Fail(errors.New("server sent an unexpected command while selecting a character"))
+			return true
+		}
 		s.Client.StateChannel <- client.StateMessage{PopToTop: true, Args: nil}
 		return true
 	}
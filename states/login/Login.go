@@ -1,7 +1,7 @@
 package login
 
 import (
-	"flag"
+	"errors"
 	"fmt"
 
 	"github.com/chimera-rpg/go-client/client"
@@ -18,6 +18,9 @@ type Login struct {
 	layout           ui.LayoutEntry
 	rememberPassword bool
 	pendingLogin     bool
+	username         string       // Username of the pending login.
+	password         string       // Password of the pending login.
+	Resume           *game.Resume // If set, we automatically log in with the stored credentials.
 }
 
 // StateID represents the current sub state of the Login state.
@@ -48,7 +51,7 @@ func (s *Login) Init(v interface{}) (next client.StateI, nextArgs interface{}, e
 
 	if v, ok := s.Client.DataManager.Config.Servers[s.Client.CurrentServer]; ok {
 		lstate.username = v.Username
-		lstate.password = v.Password
+		lstate.password = s.Client.DataManager.Credentials.Get(s.Client.CurrentServer)
 		s.rememberPassword = v.RememberPassword
 	}
 
@@ -132,14 +135,7 @@ func (s *Login) Init(v interface{}) (next client.StateI, nextArgs interface{}, e
 			Value: "LOGIN",
 			Events: ui.Events{
 				OnPressed: func(button uint8, x int32, y int32) bool {
-					if !s.pendingLogin {
-						s.pendingLogin = true
-						s.Client.Send(network.Command(network.CommandLogin{
-							Type: network.Login,
-							User: s.layout.Find("UsernameInput").Element.GetValue(),
-							Pass: s.layout.Find("PasswordInput").Element.GetValue(),
-						}))
-					}
+					s.login(s.layout.Find("UsernameInput").Element.GetValue(), s.layout.Find("PasswordInput").Element.GetValue())
 					return false
 				},
 			},
@@ -172,14 +168,13 @@ func (s *Login) Close() {
 // Loop handles our various state channels.
 func (s *Login) Loop() {
 	// Attempt to automatically log in if username and password have been provided.
-	username := flag.Lookup("username")
-	password := flag.Lookup("password")
-	if username.Value.String() != username.DefValue && password.Value.String() != username.DefValue {
-		s.Client.Send(network.Command(network.CommandLogin{
-			Type: network.Login,
-			User: username.Value.String(),
-			Pass: password.Value.String(),
-		}))
+	if s.Client.Flags.Username != "" && s.Client.Flags.Password != "" {
+		s.login(s.Client.Flags.Username, s.Client.Flags.Password)
+	} else if s.Resume != nil {
+		if sc, ok := s.Client.DataManager.Config.Servers[s.Client.CurrentServer]; ok {
+			s.Client.Log.Print("Logging back in with stored credentials.")
+			s.login(sc.Username, s.Client.DataManager.Credentials.Get(s.Client.CurrentServer))
+		}
 	}
 	for {
 		if !s.Running {
@@ -193,6 +188,10 @@ func (s *Login) Loop() {
 			}
 		case <-s.Client.ClosedChan:
 			s.Client.Log.Print("Lost connection to server.")
+			if s.Client.Flags.NonInteractive() {
+				s.Client.Fail(errors.New("lost connection to server while logging in"))
+				return
+			}
 			s.Client.StateChannel <- client.StateMessage{PopToTop: true, Args: nil}
 			return
 		case <-s.CloseChan:
@@ -201,11 +200,26 @@ func (s *Login) Loop() {
 	}
 }
 
+// login sends a login request with the given credentials unless one is already pending.
+func (s *Login) login(username, password string) {
+	if s.pendingLogin {
+		return
+	}
+	s.pendingLogin = true
+	s.username = username
+	s.password = password
+	s.Client.Send(network.Command(network.CommandLogin{
+		Type: network.Login,
+		User: username,
+		Pass: password,
+	}))
+}
+
 // HandleNet handles the network commands received in Loop().
 func (s *Login) HandleNet(cmd network.Command) bool {
 	switch t := cmd.(type) {
 	case network.CommandRejoin: // If we are sent a rejoin command, just immediately head over to game state.
-		s.Client.StateChannel <- client.StateMessage{Push: true, State: &game.Game{}, Args: nil}
+		s.Client.StateChannel <- client.StateMessage{Push: true, State: &game.Game{}, Args: s.Resume}
 		return true
 	case network.CommandBasic:
 		s.Client.Log.Print("Got basic")
@@ -214,6 +228,10 @@ func (s *Login) HandleNet(cmd network.Command) bool {
 			s.layout.Find("OutputText").Element.GetUpdateChannel() <- ui.UpdateValue{Value: msg}
 			s.Client.Log.Println(msg)
 			s.pendingLogin = false
+			if s.Client.Flags.NonInteractive() {
+				s.Client.Fail(errors.New(msg))
+				return true
+			}
 		} else if t.Type == network.Okay {
 			msg := fmt.Sprintf("Server accepted us: %s", t.String)
 			s.layout.Find("OutputText").Element.GetUpdateChannel() <- ui.UpdateValue{Value: msg}
@@ -226,19 +244,26 @@ func (s *Login) HandleNet(cmd network.Command) bool {
 			if _, ok := s.Client.DataManager.Config.Servers[serverName]; !ok {
 				s.Client.DataManager.Config.Servers[serverName] = &config.ServerConfig{}
 			}
-			s.Client.DataManager.Config.Servers[serverName].Username = s.layout.Find("UsernameInput").Element.GetValue()
+			s.Client.DataManager.Config.Servers[serverName].Username = s.username
 			if s.rememberPassword {
-				s.Client.DataManager.Config.Servers[serverName].Password = s.layout.Find("PasswordInput").Element.GetValue()
+				s.Client.DataManager.Credentials.Set(serverName, s.password)
 			} else {
-				s.Client.DataManager.Config.Servers[serverName].Password = ""
+				s.Client.DataManager.Credentials.Set(serverName, "")
+			}
+			if err := s.Client.DataManager.Credentials.Write(); err != nil {
+				s.Client.Log.Warn(err)
 			}
 			s.Client.DataManager.Config.Servers[serverName].RememberPassword = s.rememberPassword
-			s.Client.StateChannel <- client.StateMessage{Push: true, State: &CharacterSelection{}, Args: msg}
+			s.Client.StateChannel <- client.StateMessage{Push: true, State: &CharacterSelection{Resume: s.Resume}, Args: msg}
 			return true
 		}
 	default:
 		msg := fmt.Sprintf("Server sent non CommandBasic %d", t.GetType())
 		s.Client.Log.Print(msg)
+		if s.Client.Flags.NonInteractive() {
+			s.Client.Fail(errors.New(msg))
+			return true
+		}
 		s.Client.StateChannel <- client.StateMessage{PopToTop: true, Args: msg}
 		return true
 	}
@@ -0,0 +1,99 @@
+package replay
+
+import (
+	"errors"
+	"time"
+
+	"github.com/chimera-rpg/go-client/client"
+	"github.com/chimera-rpg/go-client/states/game"
+	"github.com/chimera-rpg/go-client/states/list"
+	"github.com/chimera-rpg/go-server/network"
+)
+
+// Replay is the state responsible for feeding a recorded session file into a Game state in place of a live connection.
+type Replay struct {
+	client.State
+	entries []client.SessionEntry
+	done    chan struct{}
+}
+
+// Init reads the session file passed as v and pushes the Game state.
+func (s *Replay) Init(v interface{}) (next client.StateI, nextArgs interface{}, err error) {
+	filepath, ok := v.(string)
+	if !ok {
+		return nil, nil, errors.New("replay requires a session file")
+	}
+	header, entries, err := client.ReadSession(filepath)
+	if err != nil {
+		return
+	}
+	s.Client.CurrentServer = header.Server
+	s.Client.SetCacheNamespace(header.Server)
+
+	// Handshake and login commands are not needed by the Game state, so we only keep what was received afterwards.
+	for _, e := range entries {
+		if e.Outgoing {
+			continue
+		}
+		switch t := e.Command.(type) {
+		case network.CommandFeatures:
+			s.Client.LoadAnimationsConfig(t.AnimationsConfig)
+			s.Client.Slots = t.Slots
+			s.Client.TypeHints = t.TypeHints
+		case network.CommandHandshake, network.CommandBasic, network.CommandCharacter, network.CommandSelectCharacter:
+		default:
+			s.entries = append(s.entries, e)
+		}
+	}
+
+	s.Client.Log.Printf("Replaying %d commands from %s", len(s.entries), filepath)
+
+	s.Client.Replaying = true
+	s.Client.CmdChan = make(chan network.Command)
+	s.Client.ClosedChan = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go s.Loop()
+	return
+}
+
+// Close stops the replay.
+func (s *Replay) Close() {
+	if s.done != nil {
+		close(s.done)
+	}
+	s.Client.Replaying = false
+}
+
+// Enter is called when the Game state is left, so we return to the server list.
+func (s *Replay) Enter(args ...interface{}) {
+	go func() {
+		s.Client.StateChannel <- client.StateMessage{State: &list.List{}}
+	}()
+}
+
+// Loop pushes the Game state and feeds it the recorded commands with their original timing.
+func (s *Replay) Loop() {
+	s.Client.StateChannel <- client.StateMessage{Push: true, State: &game.Game{}}
+
+	if len(s.entries) == 0 {
+		return
+	}
+	start := time.Now()
+	offset := s.entries[0].Time
+	for _, e := range s.entries {
+		if wait := e.Time - offset - time.Since(start); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-s.done:
+				return
+			}
+		}
+		select {
+		case s.Client.CmdChan <- e.Command:
+		case <-s.done:
+			return
+		}
+	}
+	s.Client.Log.Print("Replay finished")
+}
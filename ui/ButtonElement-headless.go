@@ -0,0 +1,38 @@
+//go:build headless
+// +build headless
+
+package ui
+
+// ButtonElement is the element type responsible for receiving mouse or touch
+// events.
+type ButtonElement struct {
+	BaseElement
+	measured bool
+	tw       int32 // Text width
+	th       int32 // Text height
+}
+
+// SetValue sets the text value of the button and measures it.
+func (b *ButtonElement) SetValue(value string) (err error) {
+	b.Value = value
+	if b.Context == nil {
+		return
+	}
+	b.tw, b.th = b.Context.MeasureText(b.Value)
+	if b.Style.Resize.Has(TOCONTENT) {
+		b.Style.W.Set(float64(b.tw))
+		b.Style.H.Set(float64(b.th))
+	}
+	b.measured = true
+	b.Dirty = true
+	return
+}
+
+// CalculateStyle measures the text if it hasn't been before calling
+// BaseElement.CalculateStyle()
+func (b *ButtonElement) CalculateStyle() {
+	if !b.measured {
+		b.SetValue(b.Value)
+	}
+	b.BaseElement.CalculateStyle()
+}
@@ -1,5 +1,5 @@
-//go:build !mobile
-// +build !mobile
+//go:build !mobile && !headless
+// +build !mobile,!headless
 
 package ui
 
@@ -128,63 +128,6 @@ func (w *Container) CalculateStyle() {
 	}
 }
 
-func (w *Container) AdoptChild(c ElementI) {
-	w.BaseElement.AdoptChild(c)
-	w.reflow()
-}
-
-func (w *Container) reflow() {
-	var x int32
-	var y int32
-	if w.Style.Display.Has(COLUMNS) {
-		if w.Style.Direction.Has(REVERSE) {
-			y := w.h
-			for i := len(w.Children) - 1; i >= 0; i-- {
-				child := w.Children[i]
-				switch c := child.(type) {
-				case *Container:
-					c.reflow()
-				}
-				child.CalculateStyle()
-				y -= child.GetMarginBottom()
-				y -= child.GetHeight()
-				y -= child.GetMarginTop()
-				child.GetStyle().Y.Percentage = false
-				child.GetStyle().Y.Set(float64(y))
-				child.CalculateStyle()
-			}
-		} else {
-			for _, child := range w.Children {
-				switch c := child.(type) {
-				case *Container:
-					c.reflow()
-				}
-				child.CalculateStyle()
-				y += child.GetMarginTop()
-				child.GetStyle().Y.Percentage = false
-				child.GetStyle().Y.Set(float64(y))
-				child.CalculateStyle()
-				y += child.GetHeight()
-				y += child.GetMarginBottom()
-			}
-		}
-	} else if w.Style.Display.Has(ROWS) {
-		for _, child := range w.Children {
-			switch c := child.(type) {
-			case *Container:
-				c.reflow()
-			}
-			child.CalculateStyle()
-			x += child.GetMarginLeft()
-			child.GetStyle().X.Percentage = false
-			child.GetStyle().X.Set(float64(x))
-			child.CalculateStyle()
-			x += child.GetWidth()
-			x += child.GetMarginRight()
-		}
-	}
-}
-
 // Destroy the window, clearing the SDL context and destroying the SDLWindow if it is a top-level window.
 func (w *Container) Destroy() {
 	if w.SDLTexture != nil {
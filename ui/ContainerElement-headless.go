@@ -0,0 +1,57 @@
+//go:build headless
+// +build headless
+
+package ui
+
+// Container is a UI element that contains other elements.
+type Container struct {
+	BaseElement
+	overflowY int32
+
+	gripX      int32
+	gripY      int32
+	gripW      int32
+	gripH      int32
+	gripHeldY  bool
+	gripHoverY bool
+	gripLastY  int32
+
+	ContainerRenderFunc ContainerRenderFunc
+}
+
+// Render calls the container's render function, if any, and renders its children.
+func (w *Container) Render() {
+	if w.IsHidden() {
+		return
+	}
+	if w.ContainerRenderFunc != nil {
+		w.ContainerRenderFunc(w)
+	}
+	w.BaseElement.Render()
+	for _, child := range w.BaseElement.VisibleChildren() {
+		child.RenderPost()
+	}
+}
+
+// CalculateStyle recalculates the style and reflows the Container if it is dirty. See BaseElement.CalculateStyle().
+func (w *Container) CalculateStyle() {
+	w.BaseElement.CalculateStyle()
+	if w.IsDirty() {
+		w.reflow()
+	}
+
+	if w.Style.Overflow.Has(OVERFLOWY) {
+		for _, child := range w.BaseElement.VisibleChildren() {
+			dy := (child.GetY() + child.GetHeight()) - w.h
+			if dy > 0 && dy > w.overflowY {
+				w.overflowY = dy
+			}
+		}
+		w.refreshGrippers()
+	}
+}
+
+// Destroy the container.
+func (w *Container) Destroy() {
+	w.BaseElement.Destroy()
+}
@@ -57,6 +57,65 @@ func (w *Container) GetY() int32 {
 	return 0
 }*/
 
+// AdoptChild adopts the given child and reflows the Container.
+func (w *Container) AdoptChild(c ElementI) {
+	w.BaseElement.AdoptChild(c)
+	w.reflow()
+}
+
+// reflow positions the Container's children according to its Display style.
+func (w *Container) reflow() {
+	var x int32
+	var y int32
+	if w.Style.Display.Has(COLUMNS) {
+		if w.Style.Direction.Has(REVERSE) {
+			y := w.h
+			for i := len(w.Children) - 1; i >= 0; i-- {
+				child := w.Children[i]
+				switch c := child.(type) {
+				case *Container:
+					c.reflow()
+				}
+				child.CalculateStyle()
+				y -= child.GetMarginBottom()
+				y -= child.GetHeight()
+				y -= child.GetMarginTop()
+				child.GetStyle().Y.Percentage = false
+				child.GetStyle().Y.Set(float64(y))
+				child.CalculateStyle()
+			}
+		} else {
+			for _, child := range w.Children {
+				switch c := child.(type) {
+				case *Container:
+					c.reflow()
+				}
+				child.CalculateStyle()
+				y += child.GetMarginTop()
+				child.GetStyle().Y.Percentage = false
+				child.GetStyle().Y.Set(float64(y))
+				child.CalculateStyle()
+				y += child.GetHeight()
+				y += child.GetMarginBottom()
+			}
+		}
+	} else if w.Style.Display.Has(ROWS) {
+		for _, child := range w.Children {
+			switch c := child.(type) {
+			case *Container:
+				c.reflow()
+			}
+			child.CalculateStyle()
+			x += child.GetMarginLeft()
+			child.GetStyle().X.Percentage = false
+			child.GetStyle().X.Set(float64(x))
+			child.CalculateStyle()
+			x += child.GetWidth()
+			x += child.GetMarginRight()
+		}
+	}
+}
+
 // IsContainer Returns whether or not this Element should be considered as a container.
 func (w *Container) IsContainer() bool {
 	return true
@@ -122,6 +181,9 @@ func (b *Container) OnGlobalMouseMove(x int32, y int32) bool {
 }
 
 func (b *Container) OnMouseWheel(x int32, y int32) bool {
+	if b.Events.OnMouseWheel != nil {
+		return b.BaseElement.OnMouseWheel(x, y)
+	}
 	if b.overflowY > 0 {
 		if y > 0 {
 			b.gripY -= b.gripH
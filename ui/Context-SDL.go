@@ -1,5 +1,5 @@
-//go:build !mobile
-// +build !mobile
+//go:build !mobile && !headless
+// +build !mobile,!headless
 
 package ui
 
@@ -21,6 +21,7 @@ type Context struct {
 	Manager     *DataManager
 	Font        *ttf.Font
 	OutlineFont *ttf.Font
+	Frame       uint64 // Incremented on every tick of the Loop.
 }
 
 // CreateTexture creates a regular and grayscale texture from the given image.
@@ -0,0 +1,28 @@
+//go:build headless
+// +build headless
+
+package ui
+
+// Context contains the data manager and text metrics used in place of a
+// renderer by the headless backend.
+type Context struct {
+	Manager     *DataManager
+	GlyphWidth  int32 // Width of a single glyph, used to measure text.
+	GlyphHeight int32 // Height of a single line of text.
+}
+
+// MeasureText returns the width and height the given single line of text
+// would occupy if it were rendered.
+func (c *Context) MeasureText(s string) (w, h int32) {
+	glyphWidth, glyphHeight := int32(headlessGlyphWidth), int32(headlessGlyphHeight)
+	if c != nil {
+		glyphWidth, glyphHeight = c.GlyphWidth, c.GlyphHeight
+	}
+	return int32(len([]rune(s))) * glyphWidth, glyphHeight
+}
+
+// These roughly match the default 12pt font used by the SDL backend.
+const (
+	headlessGlyphWidth  = 7
+	headlessGlyphHeight = 15
+)
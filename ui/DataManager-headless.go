@@ -0,0 +1,41 @@
+//go:build headless
+// +build headless
+
+package ui
+
+import (
+	"image"
+)
+
+// DataManager is a ui-contextualized data manager that is used to indirectly call the main client's data manager and cache as needed.
+type DataManager struct {
+	imageCache map[uint32]image.Image
+	manager    DataManagerI
+}
+
+// GetDataPath just gets the normal DataManager's path.
+func (m *DataManager) GetDataPath(s ...string) string {
+	return m.manager.GetDataPath(s...)
+}
+
+// GetCachedImage returns a ui-stored version of the image if it exists, otherwise it calls the main client's method.
+func (m *DataManager) GetCachedImage(iID uint32) (img image.Image, err error) {
+	if img, ok := m.imageCache[iID]; ok {
+		return img, nil
+	}
+	img, err = m.manager.GetCachedImage(iID)
+	if err == nil {
+		m.imageCache[iID] = img
+	}
+	return
+}
+
+// ClearCachedImage removes the ui-stored version of the image.
+func (m *DataManager) ClearCachedImage(iID uint32) {
+	delete(m.imageCache, iID)
+}
+
+// UnloadCachedImage removes the ui-stored version of the image so that its memory can be freed.
+func (m *DataManager) UnloadCachedImage(iID uint32) {
+	delete(m.imageCache, iID)
+}
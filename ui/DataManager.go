@@ -1,12 +1,19 @@
+//go:build !headless
+// +build !headless
+
 package ui
 
 import (
 	"errors"
 	"image"
+	"sort"
 
 	"github.com/veandco/go-sdl2/sdl"
 )
 
+// textureIdleFrames is the number of frames an image's textures must go unrendered before they may be unloaded.
+const textureIdleFrames = 60 * 30
+
 // DataManager is a ui-contextualized data manager that is used to indirectly call the main client's data manager and cache as needed.
 type DataManager struct {
 	imageCache    map[uint32]image.Image
@@ -31,6 +38,7 @@ func (m *DataManager) GetCachedImage(iID uint32) (img image.Image, err error) {
 	return
 }
 
+// ClearCachedImage removes the ui-stored version of the image and destroys its textures.
 func (m *DataManager) ClearCachedImage(iID uint32) {
 	delete(m.imageCache, iID)
 	if tex, ok := m.imageTextures[iID]; ok {
@@ -43,10 +51,46 @@ func (m *DataManager) ClearCachedImage(iID uint32) {
 		if tex.regularTexture != nil {
 			tex.regularTexture.Destroy()
 		}
+		tex.unloaded = true
 		delete(m.imageTextures, iID)
 	}
 }
 
+// UnloadCachedImage removes the ui-stored version of the image so that its memory can be freed, keeping its textures. The image is requested from the main client's data manager again if its textures are recreated.
+func (m *DataManager) UnloadCachedImage(iID uint32) {
+	delete(m.imageCache, iID)
+}
+
+// UnloadTextures clears the least recently rendered images until their textures fit within the given budget in bytes. Textures rendered within the last textureIdleFrames are kept. Elements recreate unloaded textures when they are next rendered.
+func (m *DataManager) UnloadTextures(budget int64, frame uint64) {
+	var total int64
+	var candidates []uint32
+	for id, tex := range m.imageTextures {
+		total += textureSize(tex)
+		if frame-tex.lastFrame > textureIdleFrames {
+			candidates = append(candidates, id)
+		}
+	}
+	if total <= budget {
+		return
+	}
+	sort.Slice(candidates, func(a, b int) bool {
+		return m.imageTextures[candidates[a]].lastFrame < m.imageTextures[candidates[b]].lastFrame
+	})
+	for _, id := range candidates {
+		if total <= budget {
+			break
+		}
+		total -= textureSize(m.imageTextures[id])
+		m.ClearCachedImage(id)
+	}
+}
+
+// textureSize returns the approximate memory used by the regular and grayscale textures of an image.
+func textureSize(tex *Image) int64 {
+	return int64(tex.width) * int64(tex.height) * 4 * 2
+}
+
 func (m *DataManager) GetImage(iID uint32) *Image {
 	return m.imageTextures[iID]
 }
@@ -35,3 +35,6 @@ type MouseEvent struct {
 	Button uint8
 	X, Y   int32
 }
+
+// KeyModNum is the numlock key modifier. Key codes and modifiers passed to elements follow SDL's values, regardless of the backend in use.
+const KeyModNum uint16 = 0x1000
@@ -1,3 +1,6 @@
+//go:build !headless
+// +build !headless
+
 package ui
 
 import "github.com/veandco/go-sdl2/sdl"
@@ -7,4 +10,6 @@ type Image struct {
 	grayscaleTexture *sdl.Texture
 	outlineTexture   *sdl.Texture
 	regularTexture   *sdl.Texture
+	lastFrame        uint64 // Frame the textures were last rendered in.
+	unloaded         bool   // Set when the textures are destroyed so that elements still referencing them recreate them.
 }
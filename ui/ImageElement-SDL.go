@@ -1,5 +1,5 @@
-//go:build !mobile
-// +build !mobile
+//go:build !mobile && !headless
+// +build !mobile,!headless
 
 package ui
 
@@ -22,6 +22,7 @@ type ImageElement struct {
 	tw             int32 // Texture width
 	th             int32 // Texture height
 	invalidated    bool
+	ownsTextures   bool // Set when the image was set with SetImage rather than from the data manager.
 }
 
 // Destroy destroys the underlying ImageElement.
@@ -56,9 +57,14 @@ func (i *ImageElement) Render() {
 		i.BaseElement.Render()
 		return
 	}
-	if i.Textures == nil {
-		i.SetImageID(i.ImageID)
+	if i.Textures == nil || i.Textures.unloaded {
+		if i.ownsTextures {
+			i.SetImage(i.Image)
+		} else {
+			i.SetImageID(i.ImageID)
+		}
 	}
+	i.Textures.lastFrame = i.Context.Frame
 	if i.Style.BackgroundColor.A > 0 {
 		dst := sdl.Rect{
 			X: i.x,
@@ -127,28 +133,43 @@ func (i *ImageElement) SetImageID(id uint32) {
 
 	i.tw = imgTextures.width
 	i.th = imgTextures.height
-	if i.Style.Resize.Has(TOCONTENT) {
-		w := float64(imgTextures.width)
-		h := float64(imgTextures.height)
-		if i.Style.ScaleX.Value > 0 {
-			if i.Style.ScaleX.Percentage {
-				w = i.Style.ScaleX.PercentOf(w)
-			} else {
-				w *= i.Style.ScaleX.Value
-			}
+	i.resizeToContent(imgTextures.width, imgTextures.height)
+
+	i.Dirty = true
+	i.invalidated = true
+}
+
+// SetImage sets the image to the given one. Its textures belong to the element and are recreated whenever the image is set.
+func (i *ImageElement) SetImage(img image.Image) {
+	if i.ownsTextures && i.Textures != nil {
+		if i.Textures.regularTexture != nil {
+			i.Textures.regularTexture.Destroy()
 		}
-		if i.Style.ScaleY.Value > 0 {
-			if i.Style.ScaleY.Percentage {
-				h = i.Style.ScaleY.PercentOf(h)
-			} else {
-				h *= i.Style.ScaleY.Value
-			}
+		if i.Textures.grayscaleTexture != nil {
+			i.Textures.grayscaleTexture.Destroy()
 		}
-
-		i.Style.W.Set(w)
-		i.Style.H.Set(h)
-		i.CalculateStyle()
 	}
+	i.ImageID = 0
+	i.Image = img
+	i.Textures = nil
+	i.ownsTextures = true
+	if img == nil {
+		return
+	}
+	tex, gray, err := i.Context.CreateTexture(img)
+	if err != nil {
+		panic(err)
+	}
+	i.Textures = &Image{
+		width:            int32(img.Bounds().Dx()),
+		height:           int32(img.Bounds().Dy()),
+		regularTexture:   tex,
+		grayscaleTexture: gray,
+	}
+
+	i.tw = i.Textures.width
+	i.th = i.Textures.height
+	i.resizeToContent(i.tw, i.th)
 
 	i.Dirty = true
 	i.invalidated = true
@@ -0,0 +1,72 @@
+//go:build headless
+// +build headless
+
+package ui
+
+import (
+	"image"
+)
+
+// ImageElement is the element responsible for an image. The headless
+// backend keeps the decoded image for sizing and pixel hits.
+type ImageElement struct {
+	BaseElement
+	Image       image.Image
+	ImageID     uint32
+	hideImage   bool
+	postOutline bool
+	grayscale   bool
+	tw          int32 // Image width
+	th          int32 // Image height
+}
+
+// SetImageID sets the image to the cached image with the given ID.
+func (i *ImageElement) SetImageID(id uint32) {
+	i.ImageID = id
+	if i.Context == nil || i.Context.Manager == nil {
+		return
+	}
+	img, err := i.Context.Manager.GetCachedImage(id)
+	if err != nil || img == nil {
+		return
+	}
+	i.Image = img
+
+	i.tw = int32(img.Bounds().Dx())
+	i.th = int32(img.Bounds().Dy())
+	i.resizeToContent(i.tw, i.th)
+
+	i.Dirty = true
+}
+
+// SetImage sets the image to the given one.
+func (i *ImageElement) SetImage(img image.Image) {
+	i.ImageID = 0
+	i.Image = img
+	i.tw, i.th = 0, 0
+	if img != nil {
+		i.tw = int32(img.Bounds().Dx())
+		i.th = int32(img.Bounds().Dy())
+		i.resizeToContent(i.tw, i.th)
+	}
+
+	i.Dirty = true
+}
+
+// UpdateOutline does nothing, as the headless backend has no textures.
+func (i *ImageElement) UpdateOutline() {
+}
+
+// PixelHit checks the image's alpha at the given position.
+func (i *ImageElement) PixelHit(x, y int32) bool {
+	if i.IsHidden() || i.Image == nil || i.w == 0 || i.h == 0 {
+		return false
+	}
+	x = x - i.ax
+	y = y - i.ay
+	rect := i.Image.Bounds()
+	x1 := int(float64(x) * float64(rect.Dx()) / float64(int(i.w)))
+	y1 := int(float64(y) * float64(rect.Dy()) / float64(int(i.h)))
+	_, _, _, a := i.Image.At(x1, y1).RGBA()
+	return a > 0
+}
@@ -50,6 +50,10 @@ func (i *ImageElement) HandleUpdate(update UpdateI) {
 		i.SetImageID(uint32(u))
 		i.OnChange()
 		i.SetDirty(true)
+	case UpdateImage:
+		i.SetImage(u.Image)
+		i.OnChange()
+		i.SetDirty(true)
 	case UpdateOutlineColor:
 		i.BaseElement.HandleUpdate(update)
 		i.UpdateOutline()
@@ -63,6 +67,33 @@ func (i *ImageElement) HandleUpdate(update UpdateI) {
 	}
 }
 
+// resizeToContent sizes the element to the given image size if its style resizes to content.
+func (i *ImageElement) resizeToContent(tw, th int32) {
+	if !i.Style.Resize.Has(TOCONTENT) {
+		return
+	}
+	w := float64(tw)
+	h := float64(th)
+	if i.Style.ScaleX.Value > 0 {
+		if i.Style.ScaleX.Percentage {
+			w = i.Style.ScaleX.PercentOf(w)
+		} else {
+			w *= i.Style.ScaleX.Value
+		}
+	}
+	if i.Style.ScaleY.Value > 0 {
+		if i.Style.ScaleY.Percentage {
+			h = i.Style.ScaleY.PercentOf(h)
+		} else {
+			h *= i.Style.ScaleY.Value
+		}
+	}
+
+	i.Style.W.Set(w)
+	i.Style.H.Set(h)
+	i.CalculateStyle()
+}
+
 func (i *ImageElement) IsGrayscale() bool {
 	return i.grayscale
 }
@@ -0,0 +1,74 @@
+//go:build headless
+// +build headless
+
+package ui
+
+import (
+	"strings"
+)
+
+// InputElement is the element that handles user input within a field.
+type InputElement struct {
+	BaseElement
+	measured      bool
+	tw            int32 // Text width
+	th            int32 // Text height
+	cursor        int
+	composition   []rune
+	isPassword    bool
+	placeholder   string
+	submitOnEnter bool
+	clearOnSubmit bool
+	blurOnSubmit  bool
+	keysHeld      map[uint8]bool
+}
+
+// SetValue sets the text value of the input field and measures it.
+func (i *InputElement) SetValue(value string) (err error) {
+	i.Value = value
+	if i.Context == nil {
+		return
+	}
+
+	renderStr := value
+	if len(value) == 0 {
+		if len(i.placeholder) == 0 {
+			renderStr = " "
+		} else {
+			renderStr = i.placeholder
+		}
+	} else if i.isPassword {
+		renderStr = strings.Repeat("*", len(value))
+	}
+
+	i.tw, i.th = i.Context.MeasureText(renderStr)
+	if i.Style.Resize.Has(TOCONTENT) {
+		i.Style.W.Set(float64(i.tw))
+		i.Style.H.Set(float64(i.th))
+	}
+	i.measured = true
+	i.Dirty = true
+	i.OnChange()
+	return
+}
+
+// CalculateStyle measures the value if it hasn't been before calculating
+// the style.
+func (i *InputElement) CalculateStyle() {
+	if !i.measured {
+		i.SetValue(i.Value)
+	}
+	i.BaseElement.CalculateStyle()
+}
+
+// OnFocus resets the held keys.
+func (i *InputElement) OnFocus() bool {
+	i.keysHeld = make(map[uint8]bool)
+	return i.BaseElement.OnFocus()
+}
+
+// OnBlur resets the held keys.
+func (i *InputElement) OnBlur() bool {
+	i.keysHeld = make(map[uint8]bool)
+	return i.BaseElement.OnBlur()
+}
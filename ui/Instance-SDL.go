@@ -1,5 +1,5 @@
-//go:build !mobile
-// +build !mobile
+//go:build !mobile && !headless
+// +build !mobile,!headless
 
 package ui
 
@@ -23,6 +23,12 @@ func (instance *Instance) Setup(dataManager DataManagerI) (err error) {
 	instance.dataManager = dataManager
 	instance.ImageLoadChan = make(chan UpdateImageID, 1000)
 	instance.ImageClearChan = make(chan UpdateImageID, 1000)
+	instance.ImageUnloadChan = make(chan UpdateImageID, 1000)
+	instance.FullscreenChan = make(chan struct{}, 1)
+	if instance.WindowGeometry.W <= 0 || instance.WindowGeometry.H <= 0 {
+		instance.WindowGeometry.W = 1280
+		instance.WindowGeometry.H = 720
+	}
 	// Initialize SDL
 	if err = sdl.Init(sdl.INIT_EVERYTHING); err != nil {
 		return err
@@ -47,19 +53,60 @@ func (instance *Instance) Setup(dataManager DataManagerI) (err error) {
 
 	err = instance.RootWindow.Setup(WindowConfig{
 		Value: "Chimera",
-		Style: `
+		Style: fmt.Sprintf(`
 			BackgroundColor 0 0 0 255
-			W 1280
-			H 720
-		`,
+			W %d
+			H %d
+		`, instance.WindowGeometry.W, instance.WindowGeometry.H),
 		RenderFunc: func(w *Window) {
 			w.Context.Renderer.Clear()
 		},
 		Context: &instance.Context,
 	})
+	if err != nil {
+		return
+	}
+	if instance.WindowGeometry.X != 0 || instance.WindowGeometry.Y != 0 {
+		instance.RootWindow.SDLWindow.SetPosition(instance.WindowGeometry.X, instance.WindowGeometry.Y)
+	}
+	if instance.WindowGeometry.Fullscreen {
+		err = instance.RootWindow.SDLWindow.SetFullscreen(sdl.WINDOW_FULLSCREEN_DESKTOP)
+	}
 	return
 }
 
+// toggleFullscreen switches the root window between fullscreen and windowed.
+func (instance *Instance) toggleFullscreen() {
+	var flags uint32
+	if !instance.WindowGeometry.Fullscreen {
+		flags = sdl.WINDOW_FULLSCREEN_DESKTOP
+	}
+	if err := instance.RootWindow.SDLWindow.SetFullscreen(flags); err != nil {
+		ShowWarning("%s", err)
+		return
+	}
+	instance.WindowGeometry.Fullscreen = !instance.WindowGeometry.Fullscreen
+	instance.windowChanged()
+}
+
+// handleWindowEvent keeps the root window's geometry in sync. The windowed geometry is kept while fullscreen so it can be restored.
+func (instance *Instance) handleWindowEvent(t *sdl.WindowEvent) {
+	if instance.WindowGeometry.Fullscreen {
+		return
+	}
+	switch t.Event {
+	case sdl.WINDOWEVENT_MOVED:
+		instance.WindowGeometry.X = t.Data1
+		instance.WindowGeometry.Y = t.Data2
+	case sdl.WINDOWEVENT_RESIZED:
+		instance.WindowGeometry.W = t.Data1
+		instance.WindowGeometry.H = t.Data2
+	default:
+		return
+	}
+	instance.windowChanged()
+}
+
 // Cleanup cleans up after our instance.
 func (instance *Instance) Cleanup() {
 	instance.RootWindow.Destroy()
@@ -80,15 +127,30 @@ func (instance *Instance) Loop() {
 			ticker.Stop()
 			return
 		}
+		instance.Context.Frame++
+		if instance.TextureMemory > 0 && instance.Context.Frame%60 == 0 {
+			instance.Context.Manager.UnloadTextures(instance.TextureMemory, instance.Context.Frame)
+		}
 
 		select {
 		case id := <-instance.ImageLoadChan:
 			instance.Context.Manager.GetCachedImage(uint32(id))
-		case id := <-instance.ImageClearChan:
-			instance.Context.Manager.ClearCachedImage(uint32(id))
+		case <-instance.FullscreenChan:
+			instance.toggleFullscreen()
 		default:
 			break
 		}
+		// Clearing is cheap, so we handle all pending clears at once.
+		for done := false; !done; {
+			select {
+			case id := <-instance.ImageClearChan:
+				instance.Context.Manager.ClearCachedImage(uint32(id))
+			case id := <-instance.ImageUnloadChan:
+				instance.Context.Manager.UnloadCachedImage(uint32(id))
+			default:
+				done = true
+			}
+		}
 
 		instance.CheckChannels(instance.RootWindow.This)
 
@@ -139,6 +201,7 @@ func (instance *Instance) Loop() {
 			case *sdl.QuitEvent:
 				instance.Running = false
 			case *sdl.WindowEvent:
+				instance.handleWindowEvent(t)
 				if t.Event == sdl.WINDOWEVENT_RESIZED {
 					instance.RootWindow.Resize(t.WindowID, t.Data1, t.Data2)
 					// Send Resized down the tree.
@@ -171,6 +234,7 @@ func (instance *Instance) WaitLoop() {
 		case *sdl.QuitEvent:
 			instance.Running = false
 		case *sdl.WindowEvent:
+			instance.handleWindowEvent(t)
 			if t.Event == sdl.WINDOWEVENT_RESIZED {
 				instance.RootWindow.Resize(t.WindowID, t.Data1, t.Data2)
 			} else if t.Event == sdl.WINDOWEVENT_CLOSE {
@@ -0,0 +1,386 @@
+//go:build headless
+// +build headless
+
+package ui
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"sort"
+	"time"
+)
+
+// MouseMoveEvent moves the mouse to the given position.
+type MouseMoveEvent struct {
+	X, Y int32
+}
+
+// MouseButtonEvent presses or releases a mouse button at the given position.
+type MouseButtonEvent struct {
+	Button  uint8
+	Pressed bool
+	X, Y    int32
+}
+
+// MouseWheelEvent scrolls the mouse wheel over the hovered elements.
+type MouseWheelEvent struct {
+	X, Y int32
+}
+
+// KeyEvent presses or releases a key. Key and Modifiers use SDL's values.
+type KeyEvent struct {
+	Key       uint8
+	Modifiers uint16
+	Pressed   bool
+	Repeat    bool
+}
+
+// TextInputEvent inputs text into the focused element.
+type TextInputEvent struct {
+	Text string
+}
+
+// WindowResizeEvent resizes the root window.
+type WindowResizeEvent struct {
+	W, H int32
+}
+
+// QuitEvent stops the Loop.
+type QuitEvent struct{}
+
+// These match the SDL button indices.
+const (
+	ButtonLeft   = 1
+	ButtonMiddle = 2
+	ButtonRight  = 3
+)
+
+// Setup sets up the headless instance. No display or font is required.
+func (instance *Instance) Setup(dataManager DataManagerI) (err error) {
+	instance.ToBeHeldElements = make(map[uint8][]ElementI, 0)
+	instance.MousedownElements = make(map[uint8][]ElementI)
+	instance.HeldElements = make(map[uint8][]ElementI)
+	instance.HeldPendingTimer = make(map[uint8]time.Time)
+	instance.dataManager = dataManager
+	instance.ImageLoadChan = make(chan UpdateImageID, 1000)
+	instance.ImageClearChan = make(chan UpdateImageID, 1000)
+	instance.ImageUnloadChan = make(chan UpdateImageID, 1000)
+	instance.EventChan = make(chan interface{}, 1000)
+	instance.FullscreenChan = make(chan struct{}, 1)
+	if instance.WindowGeometry.W <= 0 || instance.WindowGeometry.H <= 0 {
+		instance.WindowGeometry.W = 1280
+		instance.WindowGeometry.H = 720
+	}
+	instance.Context.GlyphWidth = headlessGlyphWidth
+	instance.Context.GlyphHeight = headlessGlyphHeight
+	instance.Context.Manager = &DataManager{
+		imageCache: make(map[uint32]image.Image),
+		manager:    dataManager,
+	}
+
+	err = instance.RootWindow.Setup(WindowConfig{
+		Value: "Chimera",
+		Style: fmt.Sprintf(`
+			BackgroundColor 0 0 0 255
+			W %d
+			H %d
+		`, instance.WindowGeometry.W, instance.WindowGeometry.H),
+		Context: &instance.Context,
+	})
+	return
+}
+
+// Cleanup cleans up after our instance.
+func (instance *Instance) Cleanup() {
+	instance.RootWindow.Destroy()
+}
+
+// Loop calls Step 60 times per second until the instance stops running.
+func (instance *Instance) Loop() {
+	instance.Running = true
+	ticker := time.NewTicker(time.Second / 60)
+
+	for curTime := range ticker.C {
+		if !instance.Running {
+			ticker.Stop()
+			return
+		}
+		instance.Step(curTime)
+	}
+}
+
+// PushEvent queues an event to be handled during the next Step.
+func (instance *Instance) PushEvent(event interface{}) {
+	instance.EventChan <- event
+}
+
+// Step processes all pending element channels, batch messages, and queued events once. Tests may call this directly instead of running Loop.
+func (instance *Instance) Step(curTime time.Time) {
+	for done := false; !done; {
+		select {
+		case id := <-instance.ImageLoadChan:
+			instance.Context.Manager.GetCachedImage(uint32(id))
+		case id := <-instance.ImageClearChan:
+			instance.Context.Manager.ClearCachedImage(uint32(id))
+		case id := <-instance.ImageUnloadChan:
+			instance.Context.Manager.UnloadCachedImage(uint32(id))
+		case <-instance.FullscreenChan:
+			instance.WindowGeometry.Fullscreen = !instance.WindowGeometry.Fullscreen
+			instance.windowChanged()
+		default:
+			done = true
+		}
+	}
+
+	instance.CheckChannels(instance.RootWindow.This)
+
+	// Process batch updates.
+	for done := false; !done; {
+		select {
+		case batchMessages := <-instance.RootWindow.BatchChannel:
+			for _, msg := range batchMessages {
+				switch msg := msg.(type) {
+				case BatchAdoptMessage:
+					msg.Parent.AdoptChild(msg.Target)
+				case BatchDestroyMessage:
+					msg.Target.Destroy()
+				case BatchDisownMessage:
+					msg.Parent.DisownChild(msg.Target)
+				case BatchUpdateMessage:
+					msg.Target.HandleUpdate(msg.Update)
+				}
+			}
+		default:
+			done = true
+		}
+	}
+
+	// Handle held elements.
+	for k, t := range instance.HeldPendingTimer {
+		if curTime.After(t) {
+			for _, he := range instance.ToBeHeldElements[k] {
+				if !he.OnHold(k, instance.MouseX, instance.MouseY) {
+					break
+				}
+			}
+			instance.HeldElements[k] = append(instance.HeldElements[k], instance.ToBeHeldElements[k]...)
+			instance.ToBeHeldElements[k] = make([]ElementI, 0)
+		}
+	}
+
+	for done := false; !done; {
+		select {
+		case event := <-instance.EventChan:
+			switch t := event.(type) {
+			case QuitEvent:
+				instance.Running = false
+			case WindowResizeEvent:
+				instance.RootWindow.Resize(0, t.W, t.H)
+				if !instance.WindowGeometry.Fullscreen {
+					instance.WindowGeometry.W = t.W
+					instance.WindowGeometry.H = t.H
+					instance.windowChanged()
+				}
+				instance.HandleEvent(event)
+			default:
+				instance.HandleEvent(event)
+			}
+		default:
+			done = true
+		}
+	}
+
+	if instance.RootWindow.HasDirt() {
+		instance.RootWindow.Render()
+	}
+}
+
+// HandleEvent handles the passed events from Step.
+func (instance *Instance) HandleEvent(event interface{}) {
+	switch t := event.(type) {
+	case MouseMoveEvent:
+		instance.MouseX = t.X
+		instance.MouseY = t.Y
+	case MouseButtonEvent:
+		instance.MouseX = t.X
+		instance.MouseY = t.Y
+		if instance.FocusedElement != nil {
+			if !instance.FocusedElement.Hit(t.X, t.Y) {
+				if t.Pressed {
+					instance.BlurFocusedElement()
+				}
+			}
+		}
+		if instance.HeldElement != nil {
+			if !t.Pressed && t.Button == ButtonLeft {
+				instance.HeldElement.SetHeld(false)
+				instance.HeldElement = nil
+			}
+		}
+		if !t.Pressed {
+			for _, he := range instance.HeldElements[t.Button] {
+				if !he.OnUnhold(t.Button, t.X, t.Y) {
+					break
+				}
+			}
+			instance.HeldElements[t.Button] = make([]ElementI, 0)
+			instance.ToBeHeldElements[t.Button] = make([]ElementI, 0)
+		}
+	case MouseWheelEvent:
+		for _, he := range instance.HoveredElements {
+			if !he.OnMouseWheel(t.X, t.Y) {
+				break
+			}
+		}
+	case KeyEvent:
+		if instance.FocusedElement != nil {
+			if t.Key == 27 {
+				instance.BlurFocusedElement()
+				return
+			} else if t.Key == 9 && !t.Pressed { // tab
+				if t.Modifiers&1 == 1 { // Shift
+					instance.FocusPreviousElement(instance.FocusedElement)
+				} else {
+					instance.FocusNextElement(instance.FocusedElement)
+				}
+				return
+			}
+			if t.Pressed {
+				instance.FocusedElement.OnKeyDown(t.Key, t.Modifiers, t.Repeat)
+			} else {
+				instance.FocusedElement.OnKeyUp(t.Key, t.Modifiers)
+			}
+			return
+		}
+	case TextInputEvent:
+		if instance.FocusedElement != nil {
+			instance.FocusedElement.OnTextInput(t.Text)
+		}
+		return
+	}
+	// If any events weren't handled above, we send the event down the tree.
+	instance.IterateEvent(instance.RootWindow.This, event)
+
+	switch t := event.(type) {
+	case MouseButtonEvent:
+		if t.Pressed {
+			instance.HeldPendingTimer[t.Button] = time.Now().Add(200 * time.Millisecond)
+		} else {
+			sort.Slice(instance.MousedownElements[t.Button], func(i, j int) bool {
+				return instance.MousedownElements[t.Button][i].GetZIndex() > instance.MousedownElements[t.Button][j].GetZIndex()
+			})
+			for _, e := range instance.MousedownElements[t.Button] {
+				if e.Hit(t.X, t.Y) {
+					if !e.OnPressed(t.Button, t.X, t.Y) {
+						break
+					}
+				}
+			}
+			instance.MousedownElements[t.Button] = make([]ElementI, 0)
+		}
+	}
+}
+
+// IterateEvent handles iterating an event down the entire Element tree
+// starting at the passed element.
+func (instance *Instance) IterateEvent(e ElementI, event interface{}) bool {
+	if e.IsHidden() {
+		return true
+	}
+	switch t := event.(type) {
+	case WindowResizeEvent:
+		e.OnWindowResized(t.W, t.H)
+	case MouseMoveEvent:
+		if !e.OnGlobalMouseMove(t.X, t.Y) {
+			return true
+		} else if e.Hit(t.X, t.Y) {
+			// OnMouseIn
+			existsInHovered := false
+			for _, he := range instance.HoveredElements {
+				if he == e {
+					existsInHovered = true
+					break
+				}
+			}
+			if !existsInHovered {
+				instance.HoveredElements = append(instance.HoveredElements, e)
+				e.OnMouseIn(t.X, t.Y)
+			}
+			// OnMouseMove
+			if !e.OnMouseMove(t.X, t.Y) {
+				return false
+			}
+		} else {
+			// OnMouseOut
+			for i, he := range instance.HoveredElements {
+				if he == e {
+					he.OnMouseOut(t.X, t.Y)
+					instance.HoveredElements[i] = instance.HoveredElements[len(instance.HoveredElements)-1]
+					instance.HoveredElements = instance.HoveredElements[:len(instance.HoveredElements)-1]
+					break
+				}
+			}
+		}
+	case MouseButtonEvent:
+		if !t.Pressed && !e.OnGlobalMouseButtonUp(t.Button, t.X, t.Y) {
+			return false
+		} else if t.Pressed && !e.OnGlobalMouseButtonDown(t.Button, t.X, t.Y) {
+			return false
+		} else if e.Hit(t.X, t.Y) {
+			if t.Pressed {
+				if e.CanFocus() {
+					instance.FocusElement(e)
+				}
+				if t.Button == ButtonLeft && e.CanHold() {
+					instance.HeldElement = e
+					e.SetHeld(true)
+				}
+				if !e.OnMouseButtonDown(t.Button, t.X, t.Y) {
+					return false
+				}
+				instance.ToBeHeldElements[t.Button] = append(instance.ToBeHeldElements[t.Button], e)
+				instance.MousedownElements[t.Button] = append(instance.MousedownElements[t.Button], e)
+			} else {
+				if !e.OnMouseButtonUp(t.Button, t.X, t.Y) {
+					return false
+				}
+			}
+		}
+	case KeyEvent:
+		if t.Pressed {
+			if !e.OnKeyDown(t.Key, t.Modifiers, t.Repeat) {
+				return false
+			}
+		} else {
+			if !e.OnKeyUp(t.Key, t.Modifiers) {
+				return false
+			}
+		}
+	}
+	for _, child := range e.GetChildren() {
+		if !instance.IterateEvent(child, event) {
+			return false
+		}
+	}
+	return true
+}
+
+func showWindow(kind string, format string, a ...interface{}) {
+	fmt.Fprintf(os.Stderr, "%s: %s\n", kind, fmt.Sprintf(format, a...))
+}
+
+// ShowError prints an error to stderr.
+func ShowError(format string, a ...interface{}) {
+	showWindow("error", format, a...)
+}
+
+// ShowWarning prints a warning to stderr.
+func ShowWarning(format string, a ...interface{}) {
+	showWindow("warning", format, a...)
+}
+
+// ShowInfo prints information to stderr.
+func ShowInfo(format string, a ...interface{}) {
+	showWindow("info", format, a...)
+}
@@ -0,0 +1,129 @@
+//go:build headless
+// +build headless
+
+package ui
+
+import (
+	"errors"
+	"image"
+	"testing"
+	"time"
+)
+
+// testDataManager provides no images.
+type testDataManager struct{}
+
+func (testDataManager) GetDataPath(s ...string) string {
+	return ""
+}
+
+func (testDataManager) GetCachedImage(iID uint32) (image.Image, error) {
+	return nil, errors.New("missing")
+}
+
+// newTestInstance sets up a headless instance as the GlobalInstance without running its Loop.
+func newTestInstance(t *testing.T) *Instance {
+	instance := &Instance{}
+	GlobalInstance = instance
+	if err := instance.Setup(testDataManager{}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(instance.Cleanup)
+	return instance
+}
+
+// newTestButton returns a button that counts how often it is pressed.
+func newTestButton(pressed *int) ElementI {
+	return NewButtonElement(ButtonElementConfig{
+		Style: `
+			W 100
+			H 40
+			MarginBottom 5
+		`,
+		Events: Events{
+			OnPressed: func(button uint8, x int32, y int32) bool {
+				*pressed++
+				return false
+			},
+		},
+	})
+}
+
+func TestStepLayoutAndDispatch(t *testing.T) {
+	instance := newTestInstance(t)
+	container, err := NewContainerElement(ContainerConfig{
+		Style: `
+			X 20
+			Y 10
+			W 200
+			H 300
+			Display Columns
+		`,
+		Context: &instance.Context,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var firstPressed, secondPressed int
+	first, second := newTestButton(&firstPressed), newTestButton(&secondPressed)
+
+	// Elements are adopted through their parents' channels, as states do.
+	instance.RootWindow.GetAdoptChannel() <- container
+	container.GetAdoptChannel() <- first
+	container.GetAdoptChannel() <- second
+	instance.Step(time.Now())
+	instance.Step(time.Now())
+
+	if got := container.GetChildren(); len(got) != 2 {
+		t.Fatalf("container has %d children, want 2", len(got))
+	}
+	// The container stacks its children in columns, each below the previous one and its margin.
+	layout := []struct {
+		name string
+		e    ElementI
+		y    int32
+	}{
+		{"first", first, 10},
+		{"second", second, 10 + 40 + 5},
+	}
+	for _, l := range layout {
+		if x := l.e.GetAbsoluteX(); x != 20 {
+			t.Errorf("%s button is at x %d, want 20", l.name, x)
+		}
+		if y := l.e.GetAbsoluteY(); y != l.y {
+			t.Errorf("%s button is at y %d, want %d", l.name, y, l.y)
+		}
+	}
+
+	// Clicking the second button presses only it and focuses it.
+	x, y := second.GetAbsoluteX()+50, second.GetAbsoluteY()+20
+	instance.PushEvent(MouseMoveEvent{X: x, Y: y})
+	instance.PushEvent(MouseButtonEvent{Button: ButtonLeft, Pressed: true, X: x, Y: y})
+	instance.PushEvent(MouseButtonEvent{Button: ButtonLeft, Pressed: false, X: x, Y: y})
+	instance.Step(time.Now())
+	if firstPressed != 0 || secondPressed != 1 {
+		t.Fatalf("got %d and %d presses after clicking the second button, want 0 and 1", firstPressed, secondPressed)
+	}
+	if instance.FocusedElement != second {
+		t.Fatalf("focused element is %v, want the second button", instance.FocusedElement)
+	}
+
+	// Enter presses the focused button.
+	instance.PushEvent(KeyEvent{Key: 13, Pressed: true})
+	instance.PushEvent(KeyEvent{Key: 13, Pressed: false})
+	instance.Step(time.Now())
+	if firstPressed != 0 || secondPressed != 2 {
+		t.Fatalf("got %d and %d presses after pressing enter, want 0 and 2", firstPressed, secondPressed)
+	}
+
+	// A hidden button can no longer be clicked.
+	first.GetUpdateChannel() <- UpdateHidden(true)
+	instance.Step(time.Now())
+	x, y = first.GetAbsoluteX()+50, first.GetAbsoluteY()+20
+	instance.PushEvent(MouseButtonEvent{Button: ButtonLeft, Pressed: true, X: x, Y: y})
+	instance.PushEvent(MouseButtonEvent{Button: ButtonLeft, Pressed: false, X: x, Y: y})
+	instance.Step(time.Now())
+	if firstPressed != 0 {
+		t.Fatalf("hidden button was pressed %d times", firstPressed)
+	}
+}
@@ -16,6 +16,12 @@ type Instance struct {
 	HeldPendingTimer  map[uint8]time.Time
 	ImageLoadChan     chan UpdateImageID
 	ImageClearChan    chan UpdateImageID
+	ImageUnloadChan   chan UpdateImageID   // Images the data manager unloaded, dropped from the image cache while keeping their textures.
+	EventChan         chan interface{}     // Events queued through PushEvent. Only used by the headless backend.
+	FullscreenChan    chan struct{}        // Toggles fullscreen on the root window.
+	WindowGeometry    WindowGeometry       // Geometry of the root window. Set before Setup to choose the initial geometry.
+	OnWindowChanged   func(WindowGeometry) // Called when the root window is moved, resized, or toggles fullscreen.
+	TextureMemory     int64                // Bytes of image textures to keep before the least recently rendered are unloaded. Zero keeps all textures.
 	Running           bool
 	RootWindow        Window
 	Context           Context
@@ -97,6 +103,13 @@ func (instance *Instance) CheckChannels(e ElementI) {
 
 }
 
+// windowChanged calls OnWindowChanged with the current geometry, if set.
+func (instance *Instance) windowChanged() {
+	if instance.OnWindowChanged != nil {
+		instance.OnWindowChanged(instance.WindowGeometry)
+	}
+}
+
 // BlurFocusedElement blurs the current focused element if it exists.
 func (instance *Instance) BlurFocusedElement() {
 	if instance.FocusedElement != nil {
@@ -0,0 +1,18 @@
+//go:build headless
+// +build headless
+
+package ui
+
+// MapElement is the element that handles user input and display within a
+// field.
+type MapElement struct {
+	BaseElement
+}
+
+// Destroy cleans up the MapElement's resources.
+func (m *MapElement) Destroy() {
+}
+
+// Render does nothing, as the headless backend does not render.
+func (m *MapElement) Render() {
+}
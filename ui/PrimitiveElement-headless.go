@@ -0,0 +1,10 @@
+//go:build headless
+// +build headless
+
+package ui
+
+// PrimitiveElement is the element responsible for rendering a primitive.
+type PrimitiveElement struct {
+	BaseElement
+	Shape PrimitiveShape
+}
@@ -0,0 +1,127 @@
+//go:build headless
+// +build headless
+
+package ui
+
+import (
+	"strings"
+)
+
+// TextElement is our main element for handling text. The headless backend
+// only measures text so that layout matches the SDL backend closely.
+type TextElement struct {
+	BaseElement
+	measured bool
+	tw       int32 // Text width
+	th       int32 // Text height
+	lines    []Line
+}
+
+type Line struct {
+	x, y  int32
+	w, h  int32
+	value string
+}
+
+// SetValue sets the text value for the TextElement and measures it.
+func (t *TextElement) SetValue(value string) (err error) {
+	t.Value = value
+	if t.Context == nil {
+		return
+	}
+	t.CalculateLines()
+	t.measured = true
+
+	t.Dirty = true
+	t.OnChange()
+	return
+}
+
+// GetFittedLines returns the text value as a series of lines that fit within the element's parent.
+func (t *TextElement) GetFittedLines() []Line {
+	containerW := t.Parent.GetWidth()
+	_, lineH := t.Context.MeasureText(" ")
+	var lines []Line
+	y := int32(0)
+
+	for _, l := range strings.Split(t.Value, "\n") {
+		words := strings.Split(l, " ")
+		current := ""
+		for i, word := range words {
+			candidate := word
+			if i > 0 {
+				candidate = current + " " + word
+			}
+			if w, _ := t.Context.MeasureText(candidate); w > containerW && current != "" {
+				w, _ = t.Context.MeasureText(current)
+				lines = append(lines, Line{y: y, w: w, h: lineH, value: current})
+				y += lineH
+				current = word
+			} else {
+				current = candidate
+			}
+		}
+		if current == "" {
+			current = " "
+		}
+		w, _ := t.Context.MeasureText(current)
+		lines = append(lines, Line{y: y, w: w, h: lineH, value: current})
+		y += lineH
+	}
+	return lines
+}
+
+// CalculateLines measures the text, wrapping it if the style requests it.
+func (t *TextElement) CalculateLines() {
+	if t.Style.Wrap.Has(WRAP) && t.Parent != nil {
+		t.lines = t.GetFittedLines()
+	} else {
+		value := t.Value
+		if value == "" {
+			value = " "
+		}
+		w, h := t.Context.MeasureText(value)
+		t.lines = []Line{{
+			value: value,
+			w:     w,
+			h:     h,
+		}}
+	}
+
+	var w int32
+	for _, l := range t.lines {
+		if l.w > w {
+			w = l.w
+		}
+	}
+	h := t.lines[len(t.lines)-1].y + t.lines[len(t.lines)-1].h
+
+	if t.Style.OutlineColor.A > 0 {
+		w += 4
+		h += 4
+	}
+
+	t.tw = w
+	t.th = h
+	t.w = w
+	t.h = h
+	t.Style.W.Percentage = false
+	t.Style.W.Value = float64(w)
+	t.Style.H.Percentage = false
+	t.Style.H.Value = float64(h)
+}
+
+// CalculateStyle is the same as BaseElement with the addition of measuring
+// the text if it has not yet been measured.
+func (t *TextElement) CalculateStyle() {
+	if !t.measured {
+		t.SetValue(t.Value)
+	}
+	t.BaseElement.CalculateStyle()
+}
+
+// OnWindowResized is the same as BaseElement but always remeasures the text.
+func (t *TextElement) OnWindowResized(w, h int32) {
+	t.SetValue(t.Value)
+	t.BaseElement.OnWindowResized(w, h)
+}
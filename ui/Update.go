@@ -1,6 +1,9 @@
 package ui
 
-import "image/color"
+import (
+	"image"
+	"image/color"
+)
 
 // UpdateI is the interface for our element Update messages.
 type UpdateI interface {
@@ -71,6 +74,11 @@ type UpdateForegroundColor color.NRGBA
 // UpdateImageID is used to do a lookup from the data manager to update an image element's image.
 type UpdateImageID uint32
 
+// UpdateImage replaces an image element's image with one that is not managed by the data manager.
+type UpdateImage struct {
+	Image image.Image
+}
+
 // UpdateHideImage is for hiding the rendering of an image element.
 type UpdateHideImage = bool
 
@@ -0,0 +1,52 @@
+//go:build headless
+// +build headless
+
+package ui
+
+// Window is a UI element that represents the top-level window. The headless
+// backend keeps it entirely in memory.
+type Window struct {
+	BaseElement
+
+	RenderFunc   RenderFunc
+	BatchChannel chan []BatchMessage
+}
+
+// Setup our window object according to the passed WindowConfig.
+func (w *Window) Setup(c WindowConfig) (err error) {
+	w.This = ElementI(w)
+	w.SetupChannels()
+	w.RenderFunc = c.RenderFunc
+	w.Style.Parse(WindowElementStyle)
+	w.Style.Parse(c.Style)
+	w.Context = c.Context
+	w.Value = c.Value
+	w.BatchChannel = make(chan []BatchMessage, 5000)
+	w.SetDirty(true)
+	w.CalculateStyle()
+	return nil
+}
+
+// Resize the Window to a specific width and height. The id is ignored, as there is only ever one headless window.
+func (w *Window) Resize(id uint32, width int32, height int32) (err error) {
+	w.Style.W.Set(float64(width))
+	w.Style.H.Set(float64(height))
+	w.CalculateStyle()
+	return nil
+}
+
+// Render calls the window's render function, if any, and renders its children.
+func (w *Window) Render() {
+	if w.IsHidden() {
+		return
+	}
+	if w.RenderFunc != nil {
+		w.RenderFunc(w)
+	}
+	w.BaseElement.Render()
+}
+
+// Destroy the window.
+func (w *Window) Destroy() {
+	w.BaseElement.Destroy()
+}
@@ -13,6 +13,13 @@ type WindowConfig struct {
 	Value      string
 }
 
+// WindowGeometry is the position, size, and fullscreen state of a top-level window.
+type WindowGeometry struct {
+	X, Y       int32 // Position of the window. If both are 0, the position is left up to the system.
+	W, H       int32
+	Fullscreen bool
+}
+
 // WindowElementStyle provides the default Style that is applied to all windows.
 var WindowElementStyle = `
 	ForegroundColor 0 0 0 255
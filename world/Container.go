@@ -8,40 +8,26 @@ type Container struct {
 
 // GetContainer returns the container for the given ID.
 func (w *World) GetContainer(ID uint32) *Container {
-	for _, c := range w.containers {
-		if c.ID == ID {
-			return c
-		}
-	}
-	return nil
+	return w.containers[ID]
 }
 
 // UpdateContainer updates the given container with the list of objects.
 func (w *World) UpdateContainer(ID uint32, objects []uint32) {
-	var container *Container
-	for _, c := range w.containers {
-		if c.ID == ID {
-			container = c
-			break
-		}
-	}
-	if container == nil {
+	container, ok := w.containers[ID]
+	if !ok {
 		container = &Container{
 			ID: ID,
 		}
-		w.containers = append(w.containers, container)
+		w.containers[ID] = container
 	}
 
 	// Automatically set any objects no longer in the container as not contained.
+	contained := make(map[uint32]struct{}, len(objects))
+	for _, oID := range objects {
+		contained[oID] = struct{}{}
+	}
 	for _, oID := range container.ObjectIDs {
-		has := false
-		for _, oID2 := range objects {
-			if oID2 == oID {
-				has = true
-				break
-			}
-		}
-		if !has {
+		if _, ok := contained[oID]; !ok {
 			if o := w.GetObject(oID); o != nil {
 				o.Contained = false
 			}
@@ -53,10 +39,10 @@ func (w *World) UpdateContainer(ID uint32, objects []uint32) {
 	for _, oID := range container.ObjectIDs {
 		o := w.GetObject(oID)
 		if o == nil {
-			w.AddObject(&Object{
+			o = &Object{
 				ID: oID,
-			})
-			o = w.GetObject(oID)
+			}
+			w.AddObject(o)
 		}
 		o.Contained = true
 	}
@@ -26,6 +26,7 @@ func (d *DynamicMapTile) Objects() []*Object {
 }
 
 func (d *DynamicMapTile) Refresh() {
+	d.opaque = false
 	for _, o := range d.objects {
 		if o.Opaque {
 			d.opaque = true
@@ -0,0 +1,245 @@
+package world
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+
+	"github.com/chimera-rpg/go-client/data"
+	cdata "github.com/chimera-rpg/go-server/data"
+	"github.com/sirupsen/logrus"
+)
+
+// memoryVersion is the version of the map memory file format. Files of other versions are ignored.
+const memoryVersion = 1
+
+// RememberedTile is the topmost object of a tile as it was last seen. A zero AnimationID means the tile was seen empty or never seen.
+type RememberedTile struct {
+	AnimationID uint32
+	FaceID      uint32
+	Type        uint8 // Archetype type.
+	H, W, D     int8
+}
+
+// mapMemory holds the remembered tiles of a single map, indexed like the DynamicMap's tiles.
+type mapMemory struct {
+	Version              int
+	Height, Width, Depth int
+	Tiles                []RememberedTile
+	path                 string
+	dirty                bool
+}
+
+// SetMemoryPath sets the directory map memories are read from and written to. An empty path disables map memory.
+func (w *World) SetMemoryPath(p string) {
+	w.memoryPath = p
+}
+
+// memoryFile returns the path of the memory file for the given map name.
+func (w *World) memoryFile(name string) string {
+	return filepath.Join(w.memoryPath, data.CacheNamespace(name)+".gob.gz")
+}
+
+// loadMemory reads the memory of the current map, starting a new one if there is none or if the map changed size.
+func (w *World) loadMemory(name string) {
+	m := w.GetCurrentMap()
+	w.memory = nil
+	if w.memoryPath == "" || m == nil {
+		return
+	}
+	mem := &mapMemory{
+		path: w.memoryFile(name),
+	}
+	if err := mem.read(); err != nil && !os.IsNotExist(err) {
+		w.Log.WithFields(logrus.Fields{
+			"Map": name,
+		}).Warn("[World] Could not read map memory: ", err)
+	}
+	if mem.Version != memoryVersion || mem.Height != m.height || mem.Width != m.width || mem.Depth != m.depth || len(mem.Tiles) != len(m.tiles) {
+		mem.Version = memoryVersion
+		mem.Height = m.height
+		mem.Width = m.width
+		mem.Depth = m.depth
+		mem.Tiles = make([]RememberedTile, len(m.tiles))
+	}
+	w.memory = mem
+	w.createRememberedObjects()
+}
+
+// SaveMemory writes the memory of the current map if it has changed since it was last written.
+func (w *World) SaveMemory() error {
+	if w.memory == nil || !w.memory.dirty {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(w.memory.path), os.ModePerm); err != nil {
+		return err
+	}
+	if err := w.memory.write(); err != nil {
+		return err
+	}
+	w.memory.dirty = false
+	return nil
+}
+
+func (mem *mapMemory) read() error {
+	f, err := os.Open(mem.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return gob.NewDecoder(r).Decode(mem)
+}
+
+func (mem *mapMemory) write() error {
+	f, err := os.Create(mem.path)
+	if err != nil {
+		return err
+	}
+	wr := gzip.NewWriter(f)
+	if err := gob.NewEncoder(wr).Encode(mem); err != nil {
+		wr.Close()
+		f.Close()
+		return err
+	}
+	if err := wr.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// rememberTile records the topmost object of the given visible tile. Characters are skipped, as they are unlikely to still be there when the tile is next seen.
+func (w *World) rememberTile(index int, t *DynamicMapTile) {
+	if w.memory == nil || index >= len(w.memory.Tiles) {
+		return
+	}
+	var r RememberedTile
+	for i := len(t.objects) - 1; i >= 0; i-- {
+		o := t.objects[i]
+		if o.AnimationID == 0 || o.Type == cdata.ArchetypePC.AsUint8() || o.Type == cdata.ArchetypeNPC.AsUint8() {
+			continue
+		}
+		r = RememberedTile{
+			AnimationID: o.AnimationID,
+			FaceID:      o.FaceID,
+			Type:        o.Type,
+			H:           o.H,
+			W:           o.W,
+			D:           o.D,
+		}
+		break
+	}
+	if w.memory.Tiles[index] != r {
+		w.memory.Tiles[index] = r
+		w.memory.dirty = true
+	}
+}
+
+// RememberedObject returns the greyed out object shown in place of the given remembered tile until the tile is seen again.
+func (w *World) RememberedObject(y, x, z int) *Object {
+	m := w.GetCurrentMap()
+	if m == nil || m.GetTile(y, x, z) == nil {
+		return nil
+	}
+	return w.rememberedObjects[m.Index(y, x, z)]
+}
+
+// createRememberedObjects creates objects for the remembered tiles of the current map. They are not part of the map's tiles, so they neither block vision nor can be interacted with.
+func (w *World) createRememberedObjects() {
+	m := w.GetCurrentMap()
+	w.rememberedObjects = make(map[int]*Object)
+	w.pendingRemembered = nil
+	for i, r := range w.memory.Tiles {
+		if r.AnimationID == 0 || len(m.tiles[i].objects) > 0 {
+			continue
+		}
+		z := i / (m.height * m.width)
+		y := (i % (m.height * m.width)) / m.width
+		x := i % m.width
+		o := &Object{
+			Type:             r.Type,
+			AnimationID:      r.AnimationID,
+			FaceID:           r.FaceID,
+			Y:                y,
+			X:                x,
+			Z:                z,
+			H:                r.H,
+			W:                r.W,
+			D:                r.D,
+			Remembered:       true,
+			Changed:          true,
+			VisibilityChange: true,
+		}
+		w.rememberedObjects[i] = o
+		w.dataManager.EnsureAnimation(r.AnimationID)
+		w.pendingRemembered = append(w.pendingRemembered, o)
+	}
+	w.checkRememberedObjects()
+}
+
+// checkRememberedObjects queues remembered objects for rendering once their animation and image are available.
+func (w *World) checkRememberedObjects() {
+	pending := w.pendingRemembered[:0]
+	for _, o := range w.pendingRemembered {
+		if o.Element != nil || w.rememberedObjects[w.GetCurrentMap().Index(o.Y, o.X, o.Z)] != o {
+			continue
+		}
+		if o.Frame == nil {
+			anim := w.dataManager.GetAnimation(o.AnimationID)
+			if !anim.Ready {
+				pending = append(pending, o)
+				continue
+			}
+			face := anim.GetFace(o.FaceID)
+			if len(face.Frames) == 0 {
+				continue
+			}
+			o.Animation = anim
+			o.Face = face
+			o.Frame = &(face.Frames[0])
+		}
+		img, err := w.dataManager.GetCachedImage(o.Frame.ImageID)
+		if err != nil {
+			pending = append(pending, o)
+			continue
+		}
+		o.Image = img
+		w.changedObjects = append(w.changedObjects, o)
+	}
+	w.pendingRemembered = pending
+}
+
+// forgetRememberedObject removes the remembered object of the given tile, as the tile is known again.
+func (w *World) forgetRememberedObject(y, x, z int) {
+	m := w.GetCurrentMap()
+	if m == nil || m.GetTile(y, x, z) == nil {
+		return
+	}
+	i := m.Index(y, x, z)
+	if o, ok := w.rememberedObjects[i]; ok {
+		destroyRememberedObject(o)
+		delete(w.rememberedObjects, i)
+	}
+}
+
+// forgetRememberedObjects removes all remembered objects, such as when leaving a map.
+func (w *World) forgetRememberedObjects() {
+	for _, o := range w.rememberedObjects {
+		destroyRememberedObject(o)
+	}
+	w.rememberedObjects = nil
+	w.pendingRemembered = nil
+}
+
+func destroyRememberedObject(o *Object) {
+	if o.Element != nil {
+		o.Element.GetDestroyChannel() <- true
+		o.Element = nil
+	}
+}
@@ -32,6 +32,7 @@ type Object struct {
 	Crouching                                                                                      bool // Represents if the object is crouching. Causes the rendered image to be lightly squashed in the Y axis.
 	Opaque                                                                                         bool // Represents if the object is considered to block vision.
 	Visible                                                                                        bool // Represents if the object is visible.
+	Remembered                                                                                     bool // Represents if the object stands in for a remembered tile that is not currently known. Such objects have no ID and are not part of any tile.
 	VisibilityChange                                                                               bool // Used to record if the visibility of the object has changed since last render.
 	Unblocked                                                                                      bool // Represents if the object is unblocked (should be alpha).
 	UnblockedChange                                                                                bool // Used to record if the unblocked state of the object has changed since last render.
@@ -45,8 +46,12 @@ type Object struct {
 	RenderX, RenderY, RenderZ                                                                      int         // Cached render positions, set in RenderObject after Changes is set to true.
 	Adjusted                                                                                       bool
 	AdjustX, AdjustY                                                                               int
-	FinalRenderX, FinalRenderOffsetX, FinalRenderY, FinalRenderOffsetY, FinalRenderW, FinalRenderH int  // Represents the _final_ rendering positions, including scaling.
-	RecalculateFinalRender                                                                         bool // If the final render position should be recalculated.
+	FinalRenderX, FinalRenderOffsetX, FinalRenderY, FinalRenderOffsetY, FinalRenderW, FinalRenderH int       // Represents the _final_ rendering positions, including scaling.
+	RecalculateFinalRender                                                                         bool      // If the final render position should be recalculated.
+	ElementX, ElementY, ShadowX, ShadowY                                                           int       // Positions the object's element and shadow are placed at once they finish sliding.
+	SlideX, SlideY                                                                                 float64   // Current offset from the final render position while sliding to it.
+	SlideFromX, SlideFromY                                                                         float64   // Offset the current slide started at.
+	SlideStart                                                                                     time.Time // When the current slide started.
 	HasInfo                                                                                        bool
 	InfoChange                                                                                     bool
 	Info                                                                                           []cdata.ObjectInfo
@@ -0,0 +1,283 @@
+package world
+
+import (
+	"container/heap"
+	"math"
+
+	cdata "github.com/chimera-rpg/go-server/data"
+	"github.com/chimera-rpg/go-server/network"
+)
+
+const (
+	maxPathNodes = 20000 // Maximum number of positions explored when finding a path.
+	maxPathFall  = 3     // Maximum number of tiles a path may drop down at once.
+)
+
+// PathStep is a single move of a path along with the position it is expected to end at.
+type PathStep struct {
+	Direction int // One of network's CommandCmd directions.
+	Y, X, Z   int
+}
+
+// pathMove is a direction a path may move in.
+type pathMove struct {
+	direction int
+	y, x, z   int
+	cost      float64
+}
+
+var pathMoves = []pathMove{
+	{network.North, 0, 0, -1, 1},
+	{network.South, 0, 0, 1, 1},
+	{network.East, 0, 1, 0, 1},
+	{network.West, 0, -1, 0, 1},
+	{network.Northeast, 0, 1, -1, math.Sqrt2},
+	{network.Northwest, 0, -1, -1, math.Sqrt2},
+	{network.Southeast, 0, 1, 1, math.Sqrt2},
+	{network.Southwest, 0, -1, 1, math.Sqrt2},
+}
+
+// pathVerticalMoves are only available to objects that fly or swim.
+var pathVerticalMoves = []pathMove{
+	{network.Up, 1, 0, 0, 1},
+	{network.Down, -1, 0, 0, 1},
+}
+
+// pathGrid is the known map as seen by a moving object.
+type pathGrid struct {
+	m       *DynamicMap
+	solid   []bool
+	h, w, d int // Size of the moving object.
+	flying  bool
+}
+
+// newPathGrid marks the tiles blocked by the known objects of the current map other than the given one. As the client does not know objects' matter, blocks, tiles, characters, and opaque objects are considered to block.
+func (w *World) newPathGrid(o *Object, flying bool) *pathGrid {
+	m := w.GetCurrentMap()
+	g := &pathGrid{
+		m:      m,
+		solid:  make([]bool, len(m.tiles)),
+		h:      extent(o.H),
+		w:      extent(o.W),
+		d:      extent(o.D),
+		flying: flying,
+	}
+	for _, b := range w.objects {
+		if b == o || b.Missing || b.Contained || !blocksPath(b) {
+			continue
+		}
+		oY := b.Y
+		// Like the server, tiles collide one step below their position so that they match their visual position.
+		if b.Type == cdata.ArchetypeTile.AsUint8() {
+			oY--
+		}
+		for y := oY; y < oY+extent(b.H); y++ {
+			for x := b.X; x < b.X+extent(b.W); x++ {
+				for z := b.Z; z > b.Z-extent(b.D); z-- {
+					if m.GetTile(y, x, z) != nil {
+						g.solid[m.Index(y, x, z)] = true
+					}
+				}
+			}
+		}
+	}
+	return g
+}
+
+// extent returns an object's size along an axis, treating unsized objects as one tile.
+func extent(v int8) int {
+	if v < 1 {
+		return 1
+	}
+	return int(v)
+}
+
+func blocksPath(o *Object) bool {
+	switch cdata.ArchetypeType(o.Type) {
+	case cdata.ArchetypeBlock, cdata.ArchetypeTile, cdata.ArchetypePC, cdata.ArchetypeNPC:
+		return true
+	}
+	return o.Opaque
+}
+
+// fits returns if the moving object fits entirely within the map at the given position.
+func (g *pathGrid) fits(y, x, z int) bool {
+	for sy := y; sy < y+g.h; sy++ {
+		for sx := x; sx < x+g.w; sx++ {
+			for sz := z; sz > z-g.d; sz-- {
+				if g.m.GetTile(sy, sx, sz) == nil || g.solid[g.m.Index(sy, sx, sz)] {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// supported returns if anything is beneath the moving object at the given position.
+func (g *pathGrid) supported(y, x, z int) bool {
+	if y <= 0 {
+		return true
+	}
+	for sx := x; sx < x+g.w; sx++ {
+		for sz := z; sz > z-g.d; sz-- {
+			if g.m.GetTile(y-1, sx, sz) != nil && g.solid[g.m.Index(y-1, sx, sz)] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// move returns where the moving object ends up after the given move, following the server's rules for stepping up, stepping down, and falling.
+func (g *pathGrid) move(y, x, z int, mv pathMove) (ty, tx, tz int, ok bool) {
+	ty, tx, tz = y+mv.y, x+mv.x, z+mv.z
+	if mv.y != 0 {
+		return ty, tx, tz, g.flying && g.fits(ty, tx, tz)
+	}
+	if !g.fits(ty, tx, tz) {
+		// Step up onto 1 unit blocks.
+		if !g.fits(ty+1, tx, tz) {
+			return
+		}
+		ty++
+	} else if !g.flying && g.fits(ty-1, tx, tz) && !g.fits(ty-2, tx, tz) {
+		// Step down onto 1 unit lower ground.
+		ty--
+	}
+	if g.flying {
+		return ty, tx, tz, true
+	}
+	for fall := 0; !g.supported(ty, tx, tz); fall++ {
+		if fall >= maxPathFall {
+			return
+		}
+		ty--
+	}
+	return ty, tx, tz, true
+}
+
+// pathNode is a position explored while finding a path.
+type pathNode struct {
+	index   int
+	y, x, z int
+	cost    float64 // Cost from the start.
+	score   float64 // Cost from the start plus the estimated cost to the goal.
+	parent  *pathNode
+	step    PathStep
+	heapIdx int
+}
+
+type pathHeap []*pathNode
+
+func (h pathHeap) Len() int           { return len(h) }
+func (h pathHeap) Less(i, j int) bool { return h[i].score < h[j].score }
+func (h pathHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].heapIdx = i
+	h[j].heapIdx = j
+}
+func (h *pathHeap) Push(x interface{}) {
+	n := x.(*pathNode)
+	n.heapIdx = len(*h)
+	*h = append(*h, n)
+}
+func (h *pathHeap) Pop() interface{} {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
+
+// pathEstimate returns the estimated cost between two positions.
+func pathEstimate(y1, x1, z1, y2, x2, z2 int) float64 {
+	dx := math.Abs(float64(x1 - x2))
+	dz := math.Abs(float64(z1 - z2))
+	return math.Max(dx, dz) + (math.Sqrt2-1)*math.Min(dx, dz) + math.Abs(float64(y1-y2))
+}
+
+// FindPath plans the moves the given object must make to stand at the given position over the known tiles of the current map. Objects that fly or swim may also move up and down. If the position cannot be reached, the path leads as close to it as possible. Nil is returned if no move brings the object closer.
+func (w *World) FindPath(o *Object, y, x, z int, flying bool) []PathStep {
+	m := w.GetCurrentMap()
+	if m == nil || o == nil || m.GetTile(o.Y, o.X, o.Z) == nil {
+		return nil
+	}
+	g := w.newPathGrid(o, flying)
+	moves := pathMoves
+	if flying {
+		moves = append(append([]pathMove{}, pathMoves...), pathVerticalMoves...)
+	}
+	// The goal is matched loosely in height, as the clicked position may be a step above or below where the object ends up standing.
+	isGoal := func(n *pathNode) bool {
+		return n.x == x && n.z == z && n.y >= y-1 && n.y <= y+1
+	}
+
+	start := &pathNode{index: m.Index(o.Y, o.X, o.Z), y: o.Y, x: o.X, z: o.Z}
+	start.score = pathEstimate(o.Y, o.X, o.Z, y, x, z)
+	open := &pathHeap{start}
+	nodes := map[int]*pathNode{start.index: start}
+	closed := make(map[int]bool)
+	best := start
+	for open.Len() > 0 && len(closed) < maxPathNodes {
+		n := heap.Pop(open).(*pathNode)
+		if isGoal(n) {
+			best = n
+			break
+		}
+		closed[n.index] = true
+		if n.score-n.cost < best.score-best.cost {
+			best = n
+		}
+		for _, mv := range moves {
+			ty, tx, tz, ok := g.move(n.y, n.x, n.z, mv)
+			if !ok {
+				continue
+			}
+			index := m.Index(ty, tx, tz)
+			if closed[index] {
+				continue
+			}
+			cost := n.cost + mv.cost
+			next, ok := nodes[index]
+			if ok && cost >= next.cost {
+				continue
+			}
+			if !ok {
+				next = &pathNode{index: index, y: ty, x: tx, z: tz}
+				nodes[index] = next
+			}
+			next.cost = cost
+			next.score = cost + pathEstimate(ty, tx, tz, y, x, z)
+			next.parent = n
+			next.step = PathStep{Direction: mv.direction, Y: ty, X: tx, Z: tz}
+			if ok {
+				heap.Fix(open, next.heapIdx)
+			} else {
+				heap.Push(open, next)
+			}
+		}
+	}
+
+	var steps []PathStep
+	for n := best; n.parent != nil; n = n.parent {
+		steps = append(steps, n.step)
+	}
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+	return steps
+}
+
+// StandingPosition returns the position an object stands at when on top of the given object.
+func (o *Object) StandingPosition() (y, x, z int) {
+	y = o.Y + extent(o.H)
+	if o.Type == cdata.ArchetypeTile.AsUint8() {
+		y--
+	}
+	return y, o.X, o.Z
+}
+
+// TileChanges returns a count of tile updates received, which changes whenever the known map does.
+func (w *World) TileChanges() uint64 {
+	return w.tileChanges
+}
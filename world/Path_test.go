@@ -0,0 +1,163 @@
+package world
+
+import (
+	"io"
+	"testing"
+
+	"github.com/chimera-rpg/go-client/data"
+	cdata "github.com/chimera-rpg/go-server/data"
+	"github.com/chimera-rpg/go-server/network"
+	"github.com/sirupsen/logrus"
+)
+
+// Size of the test maps. Paths lead from west to east along the middle row.
+const (
+	pathHeight = 4
+	pathWidth  = 6
+	pathDepth  = 3
+	pathMover  = 1 << 24
+)
+
+// pathBlock is a block placed on a test map.
+type pathBlock struct {
+	y, x, z int
+	h, w, d uint8
+}
+
+// wall returns blocks filling the given column of x from the ground up to the given height, leaving out the given rows of z.
+func wall(x, height int, gaps ...int) (blocks []pathBlock) {
+	for z := 0; z < pathDepth; z++ {
+		gap := false
+		for _, g := range gaps {
+			gap = gap || g == z
+		}
+		if !gap {
+			blocks = append(blocks, pathBlock{y: 0, x: x, z: z, h: uint8(height), w: 1, d: 1})
+		}
+	}
+	return
+}
+
+// newPathWorld returns a world whose map holds the given blocks and a mover of the given size at the start of the middle row.
+func newPathWorld(t *testing.T, blocks []pathBlock, h, w, d uint8) *World {
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	wld := &World{}
+	wld.Init(&data.Manager{}, l)
+	if err := wld.HandleMapCommand(network.CommandMap{
+		MapID:  1,
+		Name:   "path",
+		Height: pathHeight,
+		Width:  pathWidth,
+		Depth:  pathDepth,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	wld.CreateObjectFromPayload(pathMover, network.CommandObjectPayloadCreate{TypeID: cdata.ArchetypePC.AsUint8(), Height: h, Width: w, Depth: d})
+	wld.SyncViewTarget(pathMover, network.CommandObjectPayloadViewTarget{Height: 16, Width: 16, Depth: 16})
+	setTile(t, wld, 0, 0, pathDepth/2+int(d)/2, pathMover)
+
+	for i, b := range blocks {
+		id := uint32(i + 1)
+		wld.CreateObjectFromPayload(id, network.CommandObjectPayloadCreate{TypeID: cdata.ArchetypeBlock.AsUint8(), Height: b.h, Width: b.w, Depth: b.d})
+		setTile(t, wld, b.y, b.x, b.z, id)
+	}
+	return wld
+}
+
+func TestFindPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		blocks  []pathBlock
+		h, w, d uint8
+		flying  bool
+		goal    [3]int
+		end     [3]int // Where the path ends, which is the start if there is none.
+		climbed bool   // Whether the path steps up onto the blocks.
+	}{
+		{
+			name: "open",
+			h:    1, w: 1, d: 1,
+			goal: [3]int{0, 5, 1},
+			end:  [3]int{0, 5, 1},
+		},
+		{
+			name:   "blocked",
+			blocks: wall(3, pathHeight),
+			h:      1, w: 1, d: 1,
+			goal: [3]int{0, 5, 1},
+			end:  [3]int{0, 2, 1},
+		},
+		{
+			name:   "one step climb",
+			blocks: wall(3, 1),
+			h:      1, w: 1, d: 1,
+			goal:    [3]int{0, 5, 1},
+			end:     [3]int{0, 5, 1},
+			climbed: true,
+		},
+		{
+			name:   "step too high",
+			blocks: wall(3, 2),
+			h:      1, w: 1, d: 1,
+			goal: [3]int{0, 5, 1},
+			end:  [3]int{0, 2, 1},
+		},
+		{
+			name:   "fits through gap",
+			blocks: wall(3, pathHeight, 1),
+			h:      1, w: 1, d: 1,
+			goal: [3]int{0, 5, 1},
+			end:  [3]int{0, 5, 1},
+		},
+		{
+			// Objects extend back in Z, so a mover two deep at z 2 also fills z 1, and cannot pass the gap at z 1 from either.
+			name:   "too deep for gap",
+			blocks: wall(3, pathHeight, 1),
+			h:      1, w: 1, d: 2,
+			goal: [3]int{0, 5, 2},
+			end:  [3]int{0, 2, 2},
+		},
+		{
+			name: "walking cannot rise",
+			h:    1, w: 1, d: 1,
+			goal: [3]int{3, 0, 1},
+			end:  [3]int{0, 0, 1},
+		},
+		{
+			// Goals are matched within a step of their height, so flying paths end as soon as they are that close.
+			name: "flying",
+			h:    1, w: 1, d: 1,
+			flying: true,
+			goal:   [3]int{3, 0, 1},
+			end:    [3]int{2, 0, 1},
+		},
+		{
+			name:   "flying over a wall",
+			blocks: wall(3, pathHeight-1),
+			h:      1, w: 1, d: 1,
+			flying: true,
+			goal:   [3]int{0, 5, 1},
+			end:    [3]int{1, 5, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newPathWorld(t, tt.blocks, tt.h, tt.w, tt.d)
+			o := w.GetObject(pathMover)
+			steps := w.FindPath(o, tt.goal[0], tt.goal[1], tt.goal[2], tt.flying)
+			end := [3]int{o.Y, o.X, o.Z}
+			climbed := false
+			for _, step := range steps {
+				end = [3]int{step.Y, step.X, step.Z}
+				climbed = climbed || (step.X == 3 && step.Y == 1)
+			}
+			if end != tt.end {
+				t.Errorf("path %+v ends at %v, want %v", steps, end, tt.end)
+			}
+			if climbed != tt.climbed {
+				t.Errorf("path %+v climbed %t, want %t", steps, climbed, tt.climbed)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package world
+
+// rayTemplateOrigin is where template rays are traced from, far enough from zero that no traced tile is skipped as being out of bounds.
+const rayTemplateOrigin = 1 << 16
+
+// rayTemplate holds the tiles crossed by rays cast from a tile's origin, relative to it. As rays always start at a tile's origin, the tiles a ray crosses only depend on its direction, so they are traced once per view size rather than on every update.
+type rayTemplate struct {
+	paths map[[3]int][][3]int16
+}
+
+// path returns the tiles crossed by a ray from a tile origin to the tile at the given offset, tracing it if it has not been traced yet.
+func (t *rayTemplate) path(w *World, dy, dx, dz int) [][3]int16 {
+	key := [3]int{dy, dx, dz}
+	if p, ok := t.paths[key]; ok {
+		return p
+	}
+	var p [][3]int16
+	o := float64(rayTemplateOrigin)
+	w.rayCasts([][2][3]float64{{{o, o, o}, {o + float64(dy), o + float64(dx), o + float64(dz)}}}, 2*o, 2*o, 2*o, func(y, x, z int) bool {
+		p = append(p, [3]int16{int16(y - rayTemplateOrigin), int16(x - rayTemplateOrigin), int16(z - rayTemplateOrigin)})
+		return false
+	})
+	t.paths[key] = p
+	return p
+}
+
+// getRayTemplate returns the ray template for the given view size, tracing every ray from the view's center to its box.
+func (w *World) getRayTemplate(vhh, vwh, vdh int) *rayTemplate {
+	key := [3]int{vhh, vwh, vdh}
+	if t, ok := w.rayTemplates[key]; ok {
+		return t
+	}
+	if w.rayTemplates == nil {
+		w.rayTemplates = make(map[[3]int]*rayTemplate)
+	}
+	t := &rayTemplate{
+		paths: make(map[[3]int][][3]int16),
+	}
+	for dy := -vhh; dy < vhh; dy++ {
+		for dx := -vwh; dx < vwh; dx++ {
+			for dz := -vdh; dz < vdh; dz++ {
+				t.path(w, dy, dx, dz)
+			}
+		}
+	}
+	w.rayTemplates[key] = t
+	return t
+}
+
+// visionRay is a line of sight ray of the view object.
+type visionRay struct {
+	y, x, z int        // Origin.
+	path    [][3]int16 // Tiles crossed, relative to the origin.
+	marked  []int      // Tiles the ray currently reaches, in order. The last may be the opaque tile that stopped it.
+}
+
+// vision is the line of sight of the view object, kept so that it can be updated incrementally when tiles change.
+type vision struct {
+	m        *DynamicMap
+	rays     []visionRay
+	counts   []int32   // Count of rays reaching each tile.
+	crossing [][]int32 // Rays that reached each tile. Entries may be stale, so they are checked against the ray's marked tiles.
+	touched  []int     // Tiles with crossing entries, so they can be cleared.
+}
+
+// reset prepares the vision for the given map, clearing all rays.
+func (v *vision) reset(m *DynamicMap) {
+	if v.m != m || len(v.counts) != len(m.tiles) {
+		v.m = m
+		v.counts = make([]int32, len(m.tiles))
+		v.crossing = make([][]int32, len(m.tiles))
+		v.touched = nil
+	} else {
+		for _, i := range v.touched {
+			v.counts[i] = 0
+			v.crossing[i] = v.crossing[i][:0]
+		}
+		v.touched = v.touched[:0]
+	}
+	v.rays = v.rays[:0]
+}
+
+// cast follows the given ray until it leaves the map or reaches an opaque tile, marking the tiles it reaches. The changed callback is called for each marked tile.
+func (v *vision) cast(r int32, changed func(i int)) {
+	ray := &v.rays[r]
+	m := v.m
+	for _, p := range ray.path {
+		y, x, z := ray.y+int(p[0]), ray.x+int(p[1]), ray.z+int(p[2])
+		if y < 0 || x < 0 || z < 0 || y >= m.height || x >= m.width || z >= m.depth {
+			continue
+		}
+		i := m.Index(y, x, z)
+		ray.marked = append(ray.marked, i)
+		if v.counts[i] == 0 && len(v.crossing[i]) == 0 {
+			v.touched = append(v.touched, i)
+		}
+		v.counts[i]++
+		v.crossing[i] = append(v.crossing[i], r)
+		if changed != nil {
+			changed(i)
+		}
+		if m.tiles[i].opaque {
+			break
+		}
+	}
+}
+
+// uncast removes the marks of the given ray.
+func (v *vision) uncast(r int32, changed func(i int)) {
+	ray := &v.rays[r]
+	for _, i := range ray.marked {
+		v.counts[i]--
+		changed(i)
+	}
+	ray.marked = ray.marked[:0]
+}
+
+// raysReaching returns the rays that currently reach the given tile, dropping stale entries.
+func (v *vision) raysReaching(i int) (rays []int32) {
+	seen := make(map[int32]struct{})
+	for _, r := range v.crossing[i] {
+		if _, ok := seen[r]; ok {
+			continue
+		}
+		seen[r] = struct{}{}
+		for _, j := range v.rays[r].marked {
+			if j == i {
+				rays = append(rays, r)
+				break
+			}
+		}
+	}
+	v.crossing[i] = append(v.crossing[i][:0], rays...)
+	return
+}
+
+// setTileVisible updates the visibility of the objects in the given tile.
+func (w *World) setTileVisible(i int, visible bool) {
+	m := w.GetCurrentMap()
+	if visible {
+		w.rememberTile(i, &m.tiles[i])
+	}
+	for _, o := range m.tiles[i].objects {
+		if o.Visible != visible {
+			o.Visible = visible
+			o.VisibilityChange = true
+			w.changedObjects = append(w.changedObjects, o)
+		}
+	}
+}
+
+// updateVisibilityAt recasts only the rays reaching the given tile after its opacity changed, updating the visibility of the tiles they cross.
+func (w *World) updateVisibilityAt(y, x, z int) {
+	m := w.GetCurrentMap()
+	v := &w.vision
+	if m == nil || v.m != m || len(w.visibleTiles) != len(m.tiles) || m.GetTile(y, x, z) == nil {
+		return
+	}
+	changed := make(map[int]struct{})
+	mark := func(i int) {
+		changed[i] = struct{}{}
+	}
+	for _, r := range v.raysReaching(m.Index(y, x, z)) {
+		v.uncast(r, mark)
+		v.cast(r, mark)
+	}
+	for i := range changed {
+		visible := v.counts[i] > 0
+		if visible != w.visibleTiles[i] {
+			w.visibleTiles[i] = visible
+			w.setTileVisible(i, visible)
+		}
+	}
+}
+
+// isTileOpaque returns if the given tile of the current map blocks vision.
+func (w *World) isTileOpaque(y, x, z int) bool {
+	if t := w.GetCurrentMap().GetTile(y, x, z); t != nil {
+		return t.opaque
+	}
+	return false
+}
+
+// updateOpacityAt updates the view object's line of sight after the given tile started or stopped blocking vision.
+func (w *World) updateOpacityAt(y, x, z int) {
+	o := w.GetViewObject()
+	if o == nil {
+		return
+	}
+	w.updateVisibilityAt(y, x, z)
+	// Unblocking probes up to 25 tiles around the view object and unblocks tiles up to 16 above it.
+	if y >= o.Y-1 && y < o.Y+16 && x >= o.X-w.viewWidth/2-25 && x < o.X+w.viewWidth/2+25 && z >= o.Z-w.viewDepth/2-25 && z < o.Z+w.viewDepth/2+25 {
+		w.updateVisionUnblocking()
+	}
+}
@@ -0,0 +1,213 @@
+package world
+
+import (
+	"io"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/chimera-rpg/go-server/network"
+	"github.com/sirupsen/logrus"
+)
+
+// Size of the test maps and view.
+const (
+	visionHeight = 12
+	visionWidth  = 24
+	visionDepth  = 24
+	visionView   = 16
+	visionPlayer = 1 << 24
+)
+
+// newVisionWorld returns a world whose map has the given number of randomly placed opaque tiles and a view object at a random tile.
+func newVisionWorld(t *testing.T, r *rand.Rand, opaque int) *World {
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	w := &World{}
+	w.Init(nil, l)
+	if err := w.HandleMapCommand(network.CommandMap{
+		MapID:  1,
+		Name:   "vision",
+		Height: visionHeight,
+		Width:  visionWidth,
+		Depth:  visionDepth,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	py, px, pz := r.Intn(visionHeight-2), r.Intn(visionWidth), r.Intn(visionDepth)
+	w.SyncViewTarget(visionPlayer, network.CommandObjectPayloadViewTarget{Height: visionView, Width: visionView, Depth: visionView})
+	setTile(t, w, py, px, pz, visionPlayer)
+	p := w.GetObject(visionPlayer)
+	p.H, p.W, p.D = 2, 1, 1
+	// Sending the view object's tile again updates its line of sight with its new size.
+	setTile(t, w, py, px, pz, visionPlayer)
+
+	for id := uint32(1); id <= uint32(opaque); id++ {
+		y, x, z := r.Intn(visionHeight), r.Intn(visionWidth), r.Intn(visionDepth)
+		if y == py && x == px && z == pz {
+			continue
+		}
+		setTile(t, w, y, x, z, id)
+		w.GetObject(id).Opaque = true
+		setTile(t, w, y, x, z, id)
+	}
+	return w
+}
+
+// setTile sets the objects of the given tile.
+func setTile(t *testing.T, w *World, y, x, z int, objectIDs ...uint32) {
+	if err := w.HandleTileCommand(network.CommandTile{Y: uint32(y), X: uint32(x), Z: uint32(z), ObjectIDs: objectIDs}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// referenceVisibleTiles recomputes the visible tiles from scratch by casting every ray of the view box, as updateVisibleTiles did before it kept its rays.
+func referenceVisibleTiles(w *World) []bool {
+	o := w.GetViewObject()
+	m := w.GetCurrentMap()
+	y1 := float64(o.Y + int(o.H))
+	if y1 >= float64(m.GetHeight()) {
+		y1 = float64(m.GetHeight() - 1)
+	}
+	x1 := float64(o.X)
+	z1 := float64(o.Z)
+
+	vhh := float64(w.viewHeight / 2)
+	vwh := float64(w.viewWidth / 2)
+	vdh := float64(w.viewDepth / 2)
+	ymin := y1 - vhh
+	if ymin < 0 {
+		ymin = 0
+	}
+	ymax := y1 + vhh
+	if ymax > float64(m.GetHeight()) {
+		ymax = float64(m.GetHeight())
+	}
+	xmin := x1 - vwh
+	if xmin < 0 {
+		xmin = 0
+	}
+	xmax := x1 + vwh
+	if xmax > float64(m.GetWidth()) {
+		xmax = float64(m.GetWidth())
+	}
+	zmin := z1 - vdh
+	if zmin < 0 {
+		zmin = 0
+	}
+	zmax := z1 + vdh
+	if zmax > float64(m.GetDepth()) {
+		zmax = float64(m.GetDepth())
+	}
+
+	rays := w.getCubeRays(y1, x1, z1, int(ymin), int(xmin), int(zmin), int(ymax), int(xmax), int(zmax))
+	for _, r := range rays {
+		rays = append(rays, [2][3]float64{
+			{r[0][0], r[0][1] + float64(o.W), r[0][2] + float64(o.D)},
+			{r[1][0], r[1][1], r[1][2]},
+		})
+	}
+
+	visible := make([]bool, len(m.tiles))
+	w.rayCasts(rays, float64(m.GetHeight()), float64(m.GetWidth()), float64(m.GetDepth()), func(y, x, z int) bool {
+		i := m.Index(y, x, z)
+		visible[i] = true
+		return m.tiles[i].opaque
+	})
+	return visible
+}
+
+// checkVisibleTiles compares the world's visible tiles with a full recompute, tile by tile.
+func checkVisibleTiles(t *testing.T, w *World, step string) {
+	t.Helper()
+	want := referenceVisibleTiles(w)
+	if len(w.visibleTiles) != len(want) {
+		t.Fatalf("%s: got %d visible tiles, want %d", step, len(w.visibleTiles), len(want))
+	}
+	m := w.GetCurrentMap()
+	mismatches := 0
+	for y := 0; y < m.GetHeight(); y++ {
+		for x := 0; x < m.GetWidth(); x++ {
+			for z := 0; z < m.GetDepth(); z++ {
+				i := m.Index(y, x, z)
+				if w.visibleTiles[i] != want[i] {
+					if mismatches < 10 {
+						t.Errorf("%s: tile %d,%d,%d is visible %t, want %t", step, y, x, z, w.visibleTiles[i], want[i])
+					}
+					mismatches++
+				}
+			}
+		}
+	}
+	if mismatches > 0 {
+		t.Fatalf("%s: %d tiles differ", step, mismatches)
+	}
+}
+
+func TestUpdateVisibleTiles(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		w := newVisionWorld(t, r, 600)
+		checkVisibleTiles(t, w, "tile updates")
+		w.updateVisibleTiles()
+		checkVisibleTiles(t, w, "full update")
+	}
+}
+
+func TestUpdateOpacityAt(t *testing.T) {
+	for seed := int64(0); seed < 5; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		w := newVisionWorld(t, r, 600)
+		w.updateVisibleTiles()
+		m := w.GetCurrentMap()
+		p := w.GetViewObject()
+		// Flip the opacity of random tiles, updating only the rays reaching them.
+		for step := 0; step < 100; step++ {
+			y, x, z := r.Intn(visionHeight), r.Intn(visionWidth), r.Intn(visionDepth)
+			if y == p.Y && x == p.X && z == p.Z {
+				continue
+			}
+			tile := m.At(y, x, z)
+			tile.opaque = !tile.opaque
+			w.updateOpacityAt(y, x, z)
+			checkVisibleTiles(t, w, "opacity change")
+		}
+	}
+}
+
+func TestOpacityChanges(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	w := newVisionWorld(t, r, 600)
+	m := w.GetCurrentMap()
+
+	var opaque []*Object
+	for _, o := range w.GetObjects() {
+		if o.Opaque {
+			opaque = append(opaque, o)
+		}
+	}
+	sort.Slice(opaque, func(a, b int) bool {
+		return opaque[a].ID < opaque[b].ID
+	})
+	for i, o := range opaque {
+		if i >= 90 {
+			break
+		}
+		switch i % 3 {
+		case 0:
+			// The tile is cleared by the server.
+			setTile(t, w, o.Y, o.X, o.Z)
+			checkVisibleTiles(t, w, "tile update")
+		case 1:
+			w.DeleteObject(o.ID)
+			checkVisibleTiles(t, w, "DeleteObject")
+		case 2:
+			if !m.At(o.Y, o.X, o.Z).opaque {
+				continue
+			}
+			w.deletedObjects = append(w.deletedObjects, o.ID)
+			w.ClearDeletedObjects()
+			checkVisibleTiles(t, w, "ClearDeletedObjects")
+		}
+	}
+}
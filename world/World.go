@@ -19,8 +19,10 @@ type World struct {
 	dataManager                      *data.Manager
 	maps                             map[cdata.StringID]*DynamicMap
 	currentMap                       cdata.StringID
-	containers                       []*Container // Known containers.
-	objects                          []*Object
+	containers                       map[uint32]*Container // Known containers, by ID.
+	objects                          map[uint32]*Object    // Known objects, by ID.
+	maxExtent                        int                   // Largest height, width, or depth of any known object, used to widen ObjectsInBox's search of tiles.
+	extentStale                      bool                  // Set if an object as large as maxExtent was removed or shrunk, so that maxExtent is recomputed on its next use.
 	changedObjects                   []*Object
 	ReachCube                        [][][]struct{}
 	IntersectCube                    [][][]struct{}
@@ -31,6 +33,13 @@ type World struct {
 	viewHeight, viewWidth, viewDepth int
 	deletedObjects                   []uint32 // A list of deleted object IDs. Used and cleared during the render call.
 	visibleTiles                     []bool
+	memoryPath                       string                  // Directory map memories are stored in.
+	memory                           *mapMemory              // Remembered tiles of the current map.
+	rememberedObjects                map[int]*Object         // Objects shown for remembered tiles that are not currently known, keyed by tile index.
+	pendingRemembered                []*Object               // Remembered objects waiting for their animation or image.
+	tileChanges                      uint64                  // Count of tile updates received.
+	rayTemplates                     map[[3]int]*rayTemplate // Ray templates by view size.
+	vision                           vision                  // Line of sight of the view object.
 	Log                              *logrus.Logger
 	//
 	LeftBlocked  bool
@@ -45,7 +54,9 @@ func (w *World) Init(manager *data.Manager, l *logrus.Logger) {
 	w.Log = l
 
 	w.maps = make(map[cdata.StringID]*DynamicMap)
-	w.objects = make([]*Object, 0)
+	w.objects = make(map[uint32]*Object)
+	w.containers = make(map[uint32]*Container)
+	w.maxExtent = 1
 	w.visibleTiles = make([]bool, 0)
 	w.PendingObjectAnimations = make(map[uint32][]uint32)
 	w.PendingObjectImages = make(map[uint32][]uint32)
@@ -69,6 +80,11 @@ func (w *World) HandleMapCommand(cmd network.CommandMap) error {
 			}
 			w.maps[cmd.MapID].Init()
 		}*/
+	if err := w.SaveMemory(); err != nil {
+		w.Log.Warn("[World] Could not save map memory: ", err)
+	}
+	w.forgetRememberedObjects()
+
 	w.maps[cmd.MapID] = &DynamicMap{
 		height:       cmd.Height,
 		width:        cmd.Width,
@@ -92,13 +108,17 @@ func (w *World) HandleMapCommand(cmd network.CommandMap) error {
 			t.RemoveObject(o)
 		}
 	}
-	w.objects = make([]*Object, 0)
+	w.objects = make(map[uint32]*Object)
+	w.maxExtent = 1
+	w.extentStale = false
 
 	// Restore our known visible object if we have one.
 	if p != nil {
 		w.AddObject(p)
 	}
 
+	w.loadMemory(cmd.Name)
+
 	return nil
 }
 
@@ -173,12 +193,24 @@ func (w *World) HandleTileCommand(cmd network.CommandTile) error {
 			objects = append(objects, o)
 		}
 	}
+	wasOpaque := w.isTileOpaque(int(cmd.Y), int(cmd.X), int(cmd.Z))
 	w.maps[w.currentMap].SetTile(int(cmd.Y), int(cmd.X), int(cmd.Z), objects)
+	w.tileChanges++
+
+	// The tile is known again, so it no longer needs its remembered object.
+	w.forgetRememberedObject(int(cmd.Y), int(cmd.X), int(cmd.Z))
+	if w.IsTileVisible(int(cmd.Y), int(cmd.X), int(cmd.Z)) {
+		m := w.maps[w.currentMap]
+		i := m.Index(int(cmd.Y), int(cmd.X), int(cmd.Z))
+		w.rememberTile(i, &m.tiles[i])
+	}
 
-	// Update our visible tiles if the view object moved.
+	// Update our visible tiles if the view object moved, or only those behind the tile if it started or stopped blocking vision.
 	if viewChanged {
 		w.updateVisibleTiles()
 		w.updateVisionUnblocking()
+	} else if wasOpaque != w.isTileOpaque(int(cmd.Y), int(cmd.X), int(cmd.Z)) {
+		w.updateOpacityAt(int(cmd.Y), int(cmd.X), int(cmd.Z))
 	}
 	w.updateTileLighting(int(cmd.Y), int(cmd.X), int(cmd.Z))
 
@@ -239,6 +271,11 @@ func (w *World) CreateObjectFromPayload(oID uint32, p network.CommandObjectPaylo
 	if o != nil {
 		// Update existing object.
 		o.Type = p.TypeID
+		w.shrinkExtent(o)
+		o.H = int8(p.Height)
+		o.W = int8(p.Width)
+		o.D = int8(p.Depth)
+		w.growExtent(o)
 
 		if o.AnimationID != p.AnimationID || o.FaceID != p.FaceID {
 			// Get randomized frame start if we have the associated animation.
@@ -319,9 +356,10 @@ func (w *World) CreateObjectFromPayload(oID uint32, p network.CommandObjectPaylo
 	return o
 }
 
-// AddObject adds the given object to the objects slice.
+// AddObject adds the given object to the known objects.
 func (w *World) AddObject(o *Object) {
-	w.objects = append(w.objects, o)
+	w.objects[o.ID] = o
+	w.growExtent(o)
 	w.changedObjects = append(w.changedObjects, o)
 }
 
@@ -330,14 +368,14 @@ func (w *World) DeleteObject(oID uint32) error {
 	o := w.GetObject(oID)
 	if o != nil {
 		if t := w.GetCurrentMap().GetTile(int(o.Y), int(o.X), int(o.Z)); t != nil {
+			wasOpaque := t.opaque
 			t.RemoveObject(o)
-		}
-		for i, o2 := range w.objects {
-			if o2.ID == oID {
-				w.objects = append(w.objects[:i], w.objects[i+1:]...)
-				break
+			if wasOpaque != t.opaque {
+				w.updateOpacityAt(int(o.Y), int(o.X), int(o.Z))
 			}
 		}
+		delete(w.objects, oID)
+		w.shrinkExtent(o)
 		// Also remove the element since we moved elements to be part of objects directly.
 		if o.Element != nil {
 			o.Element.GetDestroyChannel() <- true
@@ -363,16 +401,17 @@ func (w *World) ClearDeletedObjects() {
 	for _, oID := range w.deletedObjects {
 		// Remove from owning tile.
 		if o := w.GetObject(oID); o != nil {
-			t := w.GetCurrentMap().GetTile(int(o.Y), int(o.X), int(o.Z))
-			t.RemoveObject(o)
-		}
-
-		for i, o2 := range w.objects {
-			if o2.ID == oID {
-				w.objects = append(w.objects[:i], w.objects[i+1:]...)
-				break
+			if t := w.GetCurrentMap().GetTile(int(o.Y), int(o.X), int(o.Z)); t != nil {
+				wasOpaque := t.opaque
+				t.RemoveObject(o)
+				if wasOpaque != t.opaque {
+					w.updateOpacityAt(int(o.Y), int(o.X), int(o.Z))
+				}
 			}
+			w.shrinkExtent(o)
 		}
+
+		delete(w.objects, oID)
 	}
 	w.deletedObjects = make([]uint32, 0)
 }
@@ -391,8 +430,8 @@ func (w *World) UpdateObjectInfo(oID uint32, infos []cdata.ObjectInfo) {
 	w.changedObjects = append(w.changedObjects, o)
 }
 
-// GetObjects returns an array of all objects the client knows about.
-func (w *World) GetObjects() []*Object {
+// GetObjects returns all objects the client knows about, by ID.
+func (w *World) GetObjects() map[uint32]*Object {
 	return w.objects
 }
 
@@ -406,18 +445,91 @@ func (w *World) ClearChangedObjects() {
 
 // GetObject returns a pointer to an object based upon its ID.
 func (w *World) GetObject(oID uint32) *Object {
-	for _, o := range w.objects {
-		if o.ID == oID {
-			return o
+	return w.objects[oID]
+}
+
+// objectExtent returns the largest of an object's height, width, and depth.
+func objectExtent(o *Object) int {
+	e := extent(o.H)
+	if w := extent(o.W); w > e {
+		e = w
+	}
+	if d := extent(o.D); d > e {
+		e = d
+	}
+	return e
+}
+
+// growExtent widens the search of ObjectsInBox to cover the given object.
+func (w *World) growExtent(o *Object) {
+	if e := objectExtent(o); e > w.maxExtent {
+		w.maxExtent = e
+	}
+}
+
+// shrinkExtent marks maxExtent to be recomputed if the given object, which is being removed or resized, may be the largest known object.
+func (w *World) shrinkExtent(o *Object) {
+	if objectExtent(o) >= w.maxExtent {
+		w.extentStale = true
+	}
+}
+
+// searchExtent returns the largest size of any known object, recomputing it if large objects were removed since it was last used.
+func (w *World) searchExtent() int {
+	if w.extentStale {
+		w.maxExtent = 1
+		for _, o := range w.objects {
+			w.growExtent(o)
 		}
+		w.extentStale = false
 	}
-	return nil
+	return w.maxExtent
+}
+
+// ObjectsInBox returns the objects of the current map that occupy any tile within the given box, inclusive. Tiles only hold the objects that originate in them, so the tiles searched are widened by the largest known object size to find large objects that reach into the box.
+func (w *World) ObjectsInBox(y1, x1, z1, y2, x2, z2 int) (objects []*Object) {
+	m := w.GetCurrentMap()
+	if m == nil {
+		return
+	}
+	e := w.searchExtent() - 1
+	for z := z1; z <= z2+e; z++ {
+		for y := y1 - e; y <= y2; y++ {
+			for x := x1 - e; x <= x2; x++ {
+				t := m.GetTile(y, x, z)
+				if t == nil {
+					continue
+				}
+				for _, o := range t.objects {
+					// Objects extend up in Y, right in X, and back in Z from their origin.
+					if o.Y+extent(o.H) > y1 && o.X+extent(o.W) > x1 && o.Z-extent(o.D) < z2 && o.Y <= y2 && o.X <= x2 && o.Z >= z1 {
+						objects = append(objects, o)
+					}
+				}
+			}
+		}
+	}
+	return
 }
 
 func (w *World) MarkObjectChanged(o *Object) {
 	w.changedObjects = append(w.changedObjects, o)
 }
 
+// MarkAllObjectsChanged marks every object, including remembered ones, to be laid out again, such as after the map's scale changed.
+func (w *World) MarkAllObjectsChanged() {
+	for _, o := range w.objects {
+		o.Changed = true
+		o.RecalculateFinalRender = true
+		w.changedObjects = append(w.changedObjects, o)
+	}
+	for _, o := range w.rememberedObjects {
+		o.Changed = true
+		o.RecalculateFinalRender = true
+		w.changedObjects = append(w.changedObjects, o)
+	}
+}
+
 // GetViewObject returns a pointer to the object which the view should be centered on.
 func (w *World) GetViewObject() *Object {
 	return w.viewObject
@@ -450,6 +562,64 @@ func (w *World) VisibleObjects() (objects []*Object) {
 	return
 }
 
+// IsTileVisible returns whether the given tile of the current map was visible during the last visibility update.
+func (w *World) IsTileVisible(y, x, z int) bool {
+	m := w.GetCurrentMap()
+	if m == nil || m.GetTile(y, x, z) == nil {
+		return false
+	}
+	i := m.Index(y, x, z)
+	return i < len(w.visibleTiles) && w.visibleTiles[i]
+}
+
+// SoundOcclusion returns the number of opaque tiles between the view object and the given tile, such as walls that muffle a sound coming from it.
+func (w *World) SoundOcclusion(y, x, z int) (occlusion int) {
+	o := w.GetViewObject()
+	m := w.GetCurrentMap()
+	if o == nil || m == nil {
+		return
+	}
+	// Sounds are heard from the top of the view object.
+	y1 := float64(int(o.Y) + int(o.H) - 1)
+	if y1 < 0 {
+		y1 = 0
+	}
+	rays := [][2][3]float64{{{y1, float64(o.X), float64(o.Z)}, {float64(y), float64(x), float64(z)}}}
+	w.rayCasts(rays, float64(m.GetHeight()), float64(m.GetWidth()), float64(m.GetDepth()), func(ty, tx, tz int) bool {
+		// The sound's own tile does not muffle it.
+		if ty == y && tx == x && tz == z {
+			return true
+		}
+		if m.tiles[m.Index(ty, tx, tz)].opaque {
+			occlusion++
+		}
+		return false
+	})
+	return
+}
+
+// SkyExposure returns the average sky exposure of the tiles within radius of the given tile on its level, from 0 when fully sheltered to 1 under open sky.
+func (w *World) SkyExposure(y, x, z, radius int) float64 {
+	m := w.GetCurrentMap()
+	if m == nil {
+		return 0
+	}
+	var total float64
+	var count int
+	for tx := x - radius; tx <= x+radius; tx++ {
+		for tz := z - radius; tz <= z+radius; tz++ {
+			if t := m.GetTile(y, tx, tz); t != nil {
+				total += t.Sky()
+				count++
+			}
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
+
 func (w *World) getSphereRays(yi, xi, zi int, radius float64) (targets [][2][3]float64) {
 	stackCount := 20
 	sliceCount := 20
@@ -659,49 +829,44 @@ func (w *World) updateVisibleTiles() {
 		zmax = float64(m.GetDepth())
 	}
 
-	rays := w.getCubeRays(y1, x1, z1, int(ymin), int(xmin), int(zmin), int(ymax), int(xmax), int(zmax))
-
-	visibleTiles := make([]bool, m.height*m.width*m.depth)
-
-	markTiles := func(y, x, z int) bool {
-		// NOTE: We're unsafely accessing map tiles since the results of our rayCasts are
-		visibleTiles[m.height*m.width*z+m.width*y+x] = true
-		return m.tiles[m.height*m.width*z+m.width*y+x].opaque
-		/*tile := m.GetTile(y, x, z)
-		if tile != nil {
-			visibleTiles[y][x][z] = true
-
-			if tile.opaque {
-				return true
+	// Rays are traced from the view object's head to every tile of the view box. Their paths come from the ray template of the view's size, and the rays are kept so that later tile changes only recast the rays reaching them.
+	template := w.getRayTemplate(w.viewHeight/2, w.viewWidth/2, w.viewDepth/2)
+	v := &w.vision
+	v.reset(m)
+	// This feels wrong, but we duplicate the rays and offset the origin to ensure we can see over vertical edges on character's sides.
+	origins := [][3]int{
+		{int(y1), int(x1), int(z1)},
+		{int(y1), int(x1) + int(o.W), int(z1) + int(o.D)},
+	}
+	for _, origin := range origins {
+		for y := int(ymin); y < int(ymax); y++ {
+			for x := int(xmin); x < int(xmax); x++ {
+				for z := int(zmin); z < int(zmax); z++ {
+					// Rays are reused between updates to keep their marked tiles' memory.
+					if len(v.rays) < cap(v.rays) {
+						v.rays = v.rays[:len(v.rays)+1]
+					} else {
+						v.rays = append(v.rays, visionRay{})
+					}
+					ray := &v.rays[len(v.rays)-1]
+					ray.y, ray.x, ray.z = origin[0], origin[1], origin[2]
+					ray.path = template.path(w, y-origin[0], x-origin[1], z-origin[2])
+					ray.marked = ray.marked[:0]
+				}
 			}
 		}
-		return false*/
 	}
-
-	// This feels wrong, but we duplicate the rays and offset the origin to ensure we can see over vertical edges on character's sides.
-	for _, r := range rays {
-		rays = append(rays, [2][3]float64{
-			{r[0][0], r[0][1] + float64(o.W), r[0][2] + float64(o.D)},
-			{r[1][0], r[1][1], r[1][2]},
-		})
+	for r := range v.rays {
+		v.cast(int32(r), nil)
 	}
 
-	// Now let's shoot some rays via Amanatides & Woo.
-	w.rayCasts(rays, float64(m.GetHeight()), float64(m.GetWidth()), float64(m.GetDepth()), markTiles)
+	visibleTiles := make([]bool, len(m.tiles))
+	for _, i := range v.touched {
+		visibleTiles[i] = v.counts[i] > 0
+	}
 	// Set objects no longer visible
 	for j := 0; j < len(m.tiles); j++ {
-		isVisible := visibleTiles[j]
-		for _, o := range m.tiles[j].objects {
-			if !isVisible && o.Visible {
-				o.Visible = false
-				o.VisibilityChange = true
-				w.changedObjects = append(w.changedObjects, o)
-			} else if isVisible && !o.Visible {
-				o.Visible = true
-				o.VisibilityChange = true
-				w.changedObjects = append(w.changedObjects, o)
-			}
-		}
+		w.setTileVisible(j, visibleTiles[j])
 	}
 
 	w.visibleTiles = visibleTiles
@@ -981,6 +1146,7 @@ func (w *World) CheckPendingObjectAnimations(animationID uint32) {
 		}
 		delete(w.PendingObjectAnimations, animationID)
 	}
+	w.checkRememberedObjects()
 }
 
 func (w *World) CheckPendingObjectImageIDs(imageID uint32) {
@@ -1000,6 +1166,7 @@ func (w *World) CheckPendingObjectImageIDs(imageID uint32) {
 			delete(w.PendingObjectImages, imageID)
 		}
 	}
+	w.checkRememberedObjects()
 }
 
 // UpdateCubes updates the reach and intersect cubes.
@@ -0,0 +1,315 @@
+package world
+
+import (
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/chimera-rpg/go-client/data"
+	"github.com/chimera-rpg/go-server/network"
+	"github.com/sirupsen/logrus"
+)
+
+// Size of the map and number of objects the generated command streams work with.
+const (
+	benchHeight    = 4
+	benchWidth     = 64
+	benchDepth     = 64
+	benchObjects   = 4096
+	benchInventory = 128 // Objects that may be in the player's inventory, which are never placed on the map.
+	benchContainer = 64  // Objects in the player's inventory at once.
+	benchFrame     = 32  // Commands handled between clearing the changed objects, as the game does every frame.
+	benchView      = 1 << 24
+)
+
+// benchViewTile is the tile of the view object, which the generated commands leave alone.
+var benchViewTile = [3]uint32{0, benchWidth / 2, benchDepth / 2}
+
+// benchContainerUpdate updates a container, as the game does for container payloads, which the server's network package does not define yet.
+type benchContainerUpdate struct {
+	ID      uint32
+	Objects []uint32
+}
+
+// benchStream generates a command stream resembling a busy map, keeping track of which objects are on which tile.
+type benchStream struct {
+	rand   *rand.Rand
+	tiles  map[[3]uint32][]uint32
+	where  map[uint32][3]uint32
+	live   []uint32
+	nextID uint32
+}
+
+func newBenchStream(seed int64) *benchStream {
+	return &benchStream{
+		rand:   rand.New(rand.NewSource(seed)),
+		tiles:  make(map[[3]uint32][]uint32),
+		where:  make(map[uint32][3]uint32),
+		nextID: benchInventory + 1,
+	}
+}
+
+// randomTile returns the coordinates of a random tile other than the view object's.
+func (s *benchStream) randomTile() [3]uint32 {
+	for {
+		t := [3]uint32{uint32(s.rand.Intn(benchHeight)), uint32(s.rand.Intn(benchWidth)), uint32(s.rand.Intn(benchDepth))}
+		if t != benchViewTile {
+			return t
+		}
+	}
+}
+
+// tileCommand returns the command for the current objects of the given tile.
+func (s *benchStream) tileCommand(t [3]uint32) network.CommandTile {
+	return network.CommandTile{
+		Y:         t[0],
+		X:         t[1],
+		Z:         t[2],
+		ObjectIDs: append([]uint32(nil), s.tiles[t]...),
+	}
+}
+
+// removeFromTile removes the given object from the tile it is on and returns that tile.
+func (s *benchStream) removeFromTile(oID uint32) [3]uint32 {
+	t := s.where[oID]
+	ids := s.tiles[t]
+	for i, id := range ids {
+		if id == oID {
+			s.tiles[t] = append(ids[:i], ids[i+1:]...)
+			break
+		}
+	}
+	delete(s.where, oID)
+	return t
+}
+
+// create creates a new object on a random tile.
+func (s *benchStream) create() []interface{} {
+	oID := s.nextID
+	s.nextID++
+	t := s.randomTile()
+	s.tiles[t] = append(s.tiles[t], oID)
+	s.where[oID] = t
+	s.live = append(s.live, oID)
+	return []interface{}{
+		network.CommandObject{
+			ObjectID: oID,
+			Payload: network.CommandObjectPayloadCreate{
+				AnimationID: uint32(s.rand.Intn(64)),
+				Height:      1,
+				Width:       1,
+				Depth:       1,
+			},
+		},
+		s.tileCommand(t),
+	}
+}
+
+// move moves a random object to a random tile.
+func (s *benchStream) move() []interface{} {
+	oID := s.live[s.rand.Intn(len(s.live))]
+	from := s.removeFromTile(oID)
+	to := s.randomTile()
+	s.tiles[to] = append(s.tiles[to], oID)
+	s.where[oID] = to
+	return []interface{}{s.tileCommand(from), s.tileCommand(to)}
+}
+
+// remove deletes a random object and creates another in its place, so the number of objects stays the same.
+func (s *benchStream) remove() []interface{} {
+	i := s.rand.Intn(len(s.live))
+	oID := s.live[i]
+	s.live = append(s.live[:i], s.live[i+1:]...)
+	t := s.removeFromTile(oID)
+	cmds := []interface{}{
+		network.CommandObject{
+			ObjectID: oID,
+			Payload:  network.CommandObjectPayloadDelete{},
+		},
+		s.tileCommand(t),
+	}
+	return append(cmds, s.create()...)
+}
+
+// container fills the player's inventory with random objects.
+func (s *benchStream) container() []interface{} {
+	ids := make([]uint32, benchContainer)
+	for i, j := range s.rand.Perm(benchInventory)[:benchContainer] {
+		ids[i] = uint32(j + 1)
+	}
+	return []interface{}{
+		benchContainerUpdate{
+			ID:      0,
+			Objects: ids,
+		},
+	}
+}
+
+// populate returns the commands creating the initial objects.
+func (s *benchStream) populate() (cmds []interface{}) {
+	for i := 0; i < benchObjects; i++ {
+		cmds = append(cmds, s.create()...)
+	}
+	return append(cmds, s.container()...)
+}
+
+// mixed returns n steps of moving, replacing, and storing objects, in the proportion a busy map sees them.
+func (s *benchStream) mixed(n int) (cmds []interface{}) {
+	for i := 0; i < n; i++ {
+		switch r := s.rand.Intn(10); {
+		case r < 7:
+			cmds = append(cmds, s.move()...)
+		case r < 9:
+			cmds = append(cmds, s.remove()...)
+		default:
+			cmds = append(cmds, s.container()...)
+		}
+	}
+	return
+}
+
+// newBenchWorld returns a world with a map and view object to replay command streams into.
+func newBenchWorld(b *testing.B) *World {
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	w := &World{}
+	w.Init(&data.Manager{}, l)
+	if err := w.HandleMapCommand(network.CommandMap{
+		MapID:  1,
+		Name:   "bench",
+		Height: benchHeight,
+		Width:  benchWidth,
+		Depth:  benchDepth,
+	}); err != nil {
+		b.Fatal(err)
+	}
+	w.CreateObjectFromPayload(benchView, network.CommandObjectPayloadCreate{Height: 1, Width: 1, Depth: 1})
+	w.SyncViewTarget(benchView, network.CommandObjectPayloadViewTarget{Height: 16, Width: 16, Depth: 16})
+	if err := w.HandleTileCommand(network.CommandTile{
+		Y:         benchViewTile[0],
+		X:         benchViewTile[1],
+		Z:         benchViewTile[2],
+		ObjectIDs: []uint32{benchView},
+	}); err != nil {
+		b.Fatal(err)
+	}
+	return w
+}
+
+// replay handles the given commands as the game does.
+func replay(w *World, cmds []interface{}) {
+	for i, cmd := range cmds {
+		switch c := cmd.(type) {
+		case network.CommandObject:
+			switch p := c.Payload.(type) {
+			case network.CommandObjectPayloadCreate:
+				w.CreateObjectFromPayload(c.ObjectID, p)
+			case network.CommandObjectPayloadDelete:
+				w.DeleteObject(c.ObjectID)
+			}
+		case benchContainerUpdate:
+			w.UpdateContainer(c.ID, c.Objects)
+		case network.CommandTile:
+			w.HandleTileCommand(c)
+		}
+		if i%benchFrame == 0 {
+			w.ClearChangedObjects()
+		}
+	}
+}
+
+// benchReplay replays the commands of b.N steps from the given generator into a populated world.
+func benchReplay(b *testing.B, steps func(s *benchStream, n int) []interface{}) {
+	w := newBenchWorld(b)
+	s := newBenchStream(1)
+	replay(w, s.populate())
+	cmds := steps(s, b.N)
+	b.ReportAllocs()
+	b.ResetTimer()
+	replay(w, cmds)
+}
+
+func BenchmarkReplay(b *testing.B) {
+	benchReplay(b, (*benchStream).mixed)
+}
+
+func BenchmarkHandleTileCommand(b *testing.B) {
+	benchReplay(b, func(s *benchStream, n int) (cmds []interface{}) {
+		for i := 0; i < n; i++ {
+			cmds = append(cmds, s.move()...)
+		}
+		return
+	})
+}
+
+func BenchmarkObjectCommands(b *testing.B) {
+	benchReplay(b, func(s *benchStream, n int) (cmds []interface{}) {
+		for i := 0; i < n; i++ {
+			cmds = append(cmds, s.remove()...)
+		}
+		return
+	})
+}
+
+func BenchmarkUpdateContainer(b *testing.B) {
+	benchReplay(b, func(s *benchStream, n int) (cmds []interface{}) {
+		for i := 0; i < n; i++ {
+			cmds = append(cmds, s.container()...)
+		}
+		return
+	})
+}
+
+func BenchmarkGetObject(b *testing.B) {
+	w := newBenchWorld(b)
+	s := newBenchStream(1)
+	replay(w, s.populate())
+	ids := make([]uint32, b.N)
+	for i := range ids {
+		ids[i] = s.live[s.rand.Intn(len(s.live))]
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for _, oID := range ids {
+		if w.GetObject(oID) == nil {
+			b.Fatalf("missing object %d", oID)
+		}
+	}
+}
+
+func BenchmarkDeleteObject(b *testing.B) {
+	w := newBenchWorld(b)
+	s := newBenchStream(1)
+	replay(w, s.populate())
+	ids := make([]uint32, b.N)
+	for i := range ids {
+		ids[i] = s.live[s.rand.Intn(len(s.live))]
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for _, oID := range ids {
+		o := w.GetObject(oID)
+		w.DeleteObject(oID)
+		// Add the object back so that the number of objects stays the same.
+		w.AddObject(o)
+		if len(w.changedObjects) >= benchFrame {
+			w.ClearChangedObjects()
+		}
+	}
+}
+
+func BenchmarkObjectsInBox(b *testing.B) {
+	w := newBenchWorld(b)
+	s := newBenchStream(1)
+	replay(w, s.populate())
+	boxes := make([][2]int, b.N)
+	for i := range boxes {
+		boxes[i] = [2]int{s.rand.Intn(benchWidth), s.rand.Intn(benchDepth)}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for _, c := range boxes {
+		// Boxes span the map's height, as the minimap's do.
+		w.ObjectsInBox(0, c[0], c[1], benchHeight-1, c[0]+15, c[1]+15)
+	}
+}
@@ -0,0 +1,170 @@
+package world
+
+import (
+	"io"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/chimera-rpg/go-client/data"
+	"github.com/chimera-rpg/go-server/network"
+	"github.com/sirupsen/logrus"
+)
+
+// Size of the test map and its number of objects.
+const (
+	boxHeight  = 8
+	boxWidth   = 16
+	boxDepth   = 16
+	boxObjects = 200
+	boxView    = 1 << 24
+)
+
+// newBoxWorld returns a world whose map has the given number of objects of random sizes at random tiles, numbered from 1.
+func newBoxWorld(t *testing.T, r *rand.Rand, count int) *World {
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	w := &World{}
+	w.Init(&data.Manager{}, l)
+	if err := w.HandleMapCommand(network.CommandMap{
+		MapID:  1,
+		Name:   "box",
+		Height: boxHeight,
+		Width:  boxWidth,
+		Depth:  boxDepth,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	w.CreateObjectFromPayload(boxView, network.CommandObjectPayloadCreate{Height: 1, Width: 1, Depth: 1})
+	w.SyncViewTarget(boxView, network.CommandObjectPayloadViewTarget{Height: 16, Width: 16, Depth: 16})
+	setTile(t, w, 0, 0, 0, boxView)
+
+	tiles := make(map[[3]int][]uint32)
+	for id := uint32(1); id <= uint32(count); id++ {
+		w.CreateObjectFromPayload(id, network.CommandObjectPayloadCreate{
+			Height: uint8(1 + r.Intn(3)),
+			Width:  uint8(1 + r.Intn(3)),
+			Depth:  uint8(1 + r.Intn(4)),
+		})
+		c := [3]int{r.Intn(boxHeight), r.Intn(boxWidth), r.Intn(boxDepth)}
+		if c == [3]int{0, 0, 0} {
+			c[0] = 1
+		}
+		tiles[c] = append(tiles[c], id)
+	}
+	for c, ids := range tiles {
+		setTile(t, w, c[0], c[1], c[2], ids...)
+	}
+	return w
+}
+
+// bruteObjectsInBox returns the IDs of the objects that occupy any tile of the given box, checking every tile of every object.
+func bruteObjectsInBox(w *World, y1, x1, z1, y2, x2, z2 int) (ids []uint32) {
+	for _, o := range w.objects {
+		found := false
+		for y := o.Y; y < o.Y+extent(o.H) && !found; y++ {
+			for x := o.X; x < o.X+extent(o.W) && !found; x++ {
+				// Objects extend back in Z from their origin.
+				for z := o.Z; z > o.Z-extent(o.D) && !found; z-- {
+					found = y >= y1 && y <= y2 && x >= x1 && x <= x2 && z >= z1 && z <= z2
+				}
+			}
+		}
+		if found {
+			ids = append(ids, o.ID)
+		}
+	}
+	sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+	return
+}
+
+// checkObjectsInBox compares ObjectsInBox with a brute force scan over random boxes.
+func checkObjectsInBox(t *testing.T, w *World, r *rand.Rand, step string) {
+	t.Helper()
+	for i := 0; i < 200; i++ {
+		y1, x1, z1 := r.Intn(boxHeight), r.Intn(boxWidth), r.Intn(boxDepth)
+		y2, x2, z2 := y1+r.Intn(3), x1+r.Intn(4), z1+r.Intn(4)
+		var got []uint32
+		for _, o := range w.ObjectsInBox(y1, x1, z1, y2, x2, z2) {
+			got = append(got, o.ID)
+		}
+		sort.Slice(got, func(a, b int) bool { return got[a] < got[b] })
+		want := bruteObjectsInBox(w, y1, x1, z1, y2, x2, z2)
+		if len(got) != len(want) {
+			t.Fatalf("%s: box %d,%d,%d-%d,%d,%d has objects %v, want %v", step, y1, x1, z1, y2, x2, z2, got, want)
+		}
+		for j := range got {
+			if got[j] != want[j] {
+				t.Fatalf("%s: box %d,%d,%d-%d,%d,%d has objects %v, want %v", step, y1, x1, z1, y2, x2, z2, got, want)
+			}
+		}
+	}
+}
+
+func TestObjectsInBox(t *testing.T) {
+	for seed := int64(0); seed < 5; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		w := newBoxWorld(t, r, boxObjects)
+		checkObjectsInBox(t, w, r, "populated")
+	}
+}
+
+func TestObjectsInBoxDepth(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	w := newBoxWorld(t, r, 0)
+	w.CreateObjectFromPayload(1, network.CommandObjectPayloadCreate{Height: 1, Width: 1, Depth: 3})
+	setTile(t, w, 2, 4, 6, 1)
+
+	tests := []struct {
+		name  string
+		z1    int
+		z2    int
+		found bool
+	}{
+		{"origin", 6, 6, true},
+		{"back", 4, 4, true},
+		{"behind", 3, 3, false},
+		{"front", 7, 7, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if found := len(w.ObjectsInBox(2, 4, tt.z1, 2, 4, tt.z2)) > 0; found != tt.found {
+				t.Errorf("found %t, want %t", found, tt.found)
+			}
+		})
+	}
+}
+
+func TestObjectsInBoxExtent(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	w := newBoxWorld(t, r, boxObjects)
+	w.CreateObjectFromPayload(boxObjects+1, network.CommandObjectPayloadCreate{Height: 6, Width: 6, Depth: 6})
+	setTile(t, w, 0, 8, 8, boxObjects+1)
+	checkObjectsInBox(t, w, r, "large object")
+	if e := w.searchExtent(); e != 6 {
+		t.Fatalf("got extent %d, want 6", e)
+	}
+
+	w.DeleteObject(boxObjects + 1)
+	checkObjectsInBox(t, w, r, "DeleteObject")
+	if e := w.searchExtent(); e != 4 {
+		t.Fatalf("got extent %d after removing the largest object, want 4", e)
+	}
+
+	// Shrinking every object to a single tile also shrinks the search.
+	for _, o := range w.GetObjects() {
+		w.CreateObjectFromPayload(o.ID, network.CommandObjectPayloadCreate{Height: 1, Width: 1, Depth: 1})
+	}
+	checkObjectsInBox(t, w, r, "resized")
+	if e := w.searchExtent(); e != 1 {
+		t.Fatalf("got extent %d after resizing, want 1", e)
+	}
+
+	for _, o := range w.GetObjects() {
+		if o.ID != boxView {
+			w.deletedObjects = append(w.deletedObjects, o.ID)
+		}
+	}
+	w.ClearDeletedObjects()
+	checkObjectsInBox(t, w, r, "ClearDeletedObjects")
+}